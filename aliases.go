@@ -0,0 +1,109 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/psawicki5/goimports-reviser/v2/reviser"
+)
+
+const (
+	aliasesCmd    = "aliases"
+	aliasesFixArg = "fix"
+)
+
+func isAliasesCommand(args []string) bool {
+	return len(args) > 0 && args[0] == aliasesCmd
+}
+
+// runAliasesCommand reports import paths which are imported under different names in the files. With -fix, the
+// proposed names are set for all imports of the paths by revising the files with the flags of the tool.
+func runAliasesCommand(args []string) {
+	flags := flag.NewFlagSet(aliasesCmd, flag.ExitOnError)
+	shouldFix := flags.Bool(
+		aliasesFixArg,
+		false,
+		"Set the proposed alias for all imports of the reported paths and rename their usages. Optional parameter.",
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s %s: [flags] [path ...]\n", os.Args[0], aliasesCmd)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := validateRequiredParam(flags.Args()); err != nil {
+		fmt.Printf("%s\n\n", err)
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	files, err := collectFiles(flags.Args(), isGeneratedIncluded())
+	if err != nil {
+		log.Fatalf("failed to collect files: %+v", errors.WithStack(err))
+	}
+
+	aliasPolicy, err := parseAliasMap(aliasMap)
+	if err != nil {
+		log.Fatalf(`invalid -%s "%s" specified: %s`, aliasMapArg, aliasMap, err)
+	}
+
+	var (
+		hasErrors bool
+		names     = reviser.ImportNames{}
+		fileNames = make(map[string]map[string]string, len(files))
+		fset      = token.NewFileSet()
+	)
+	for _, filePath := range files {
+		f, err := parser.ParseFile(fset, filePath, nil, parser.ImportsOnly)
+		if err != nil {
+			hasErrors = true
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+
+		names.Add(f)
+		fileNames[filePath] = reviser.FileImportNames(f)
+	}
+
+	inconsistencies, err := names.Inconsistencies(aliasPolicy)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, inconsistent := range inconsistencies {
+		counts := make([]string, 0, len(inconsistent.Names))
+		for _, name := range inconsistent.Names {
+			counts = append(counts, fmt.Sprintf("%s(%d)", name.Name, name.Count))
+		}
+
+		fmt.Printf("%s: %s, proposed: %s\n", inconsistent.ImportPath, strings.Join(counts, ", "), inconsistent.Proposed)
+	}
+
+	if *shouldFix && len(inconsistencies) > 0 {
+		// only files which use other names are revised
+		var filesToFix []string
+		for _, filePath := range files {
+			if reviser.HasOtherNames(fileNames[filePath], inconsistencies) {
+				filesToFix = append(filesToFix, filePath)
+			}
+		}
+
+		if !reviseAll(filesToFix, newReviser(reviser.ProposedAliases(inconsistencies)), nil) {
+			hasErrors = true
+		}
+	}
+
+	if hasErrors {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/psawicki5/goimports-reviser/v2/pkg/cache"
+	"github.com/psawicki5/goimports-reviser/v2/pkg/module"
+)
+
+const (
+	cacheCmd      = "cache"
+	cacheCleanCmd = "clean"
+	cacheStatsCmd = "stats"
+)
+
+// cacheIgnoredArgs are flags which don't affect the result of revising
+var cacheIgnoredArgs = map[string]struct{}{
+	filePathArg:         {},
+	versionArg:          {},
+	outputArg:           {},
+	concurrencyArg:      {},
+	concurrencyShortArg: {},
+	useCacheArg:         {},
+	cacheDirArg:         {},
+	timeoutArg:          {},
+	includeGeneratedArg: {},
+}
+
+// cacheBypassingArgs are flags, which make results depend on packages of the module. Packages are not covered by the
+// cache key(only go.mod and go.sum are), so the cache isn't used with these flags.
+var cacheBypassingArgs = []string{addMissingImportsArg, resolveConflictsArg, fixAliasesArg, rmDotImportsArg}
+
+// fileCache wraps cache.Cache with the data which is the same for all files of the run
+type fileCache struct {
+	*cache.Cache
+
+	optionsHash string
+	version     string
+
+	mu           sync.Mutex
+	moduleHashes map[string]string
+}
+
+func isCacheCommand(args []string) bool {
+	return len(args) == 2 && args[0] == cacheCmd && (args[1] == cacheCleanCmd || args[1] == cacheStatsCmd)
+}
+
+func runCacheCommand(subCmd string) {
+	c, err := openCache()
+	if err != nil {
+		log.Fatalf("%+v", errors.WithStack(err))
+	}
+
+	switch subCmd {
+	case cacheCleanCmd:
+		if err := c.Clean(); err != nil {
+			log.Fatalf("failed to clean cache(%s): %+v", c.Dir(), errors.WithStack(err))
+		}
+	case cacheStatsCmd:
+		stats, err := c.Stats()
+		if err != nil {
+			log.Fatalf("failed to read cache(%s): %+v", c.Dir(), errors.WithStack(err))
+		}
+
+		fmt.Printf("dir: %s\nfiles: %d\nsize: %d\n", stats.Dir, stats.Files, stats.Size)
+	}
+}
+
+func openCache() (*cache.Cache, error) {
+	if cacheDir != "" {
+		return cache.New(cacheDir), nil
+	}
+
+	dir, err := cache.DefaultDir()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to determine cache dir")
+	}
+
+	return cache.New(dir), nil
+}
+
+func newFileCache() (*fileCache, error) {
+	c, err := openCache()
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := toolVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	return &fileCache{
+		Cache:        c,
+		optionsHash:  cache.Hash([]byte(optionsFingerprint())),
+		version:      version,
+		moduleHashes: map[string]string{},
+	}, nil
+}
+
+// entry returns the cache entry of the file with the content
+func (c *fileCache) entry(filePath string, content []byte) (*cache.Entry, error) {
+	moduleHash, err := c.moduleHash(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cache.Entry{
+		ContentHash: cache.Hash(content),
+		OptionsHash: c.optionsHash,
+		ModuleHash:  moduleHash,
+		Version:     c.version,
+	}, nil
+}
+
+func (c *fileCache) moduleHash(filePath string) (string, error) {
+	goModRootPath, err := module.GoModRootPath(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	if goModRootPath == "" {
+		return "", nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if hash, ok := c.moduleHashes[goModRootPath]; ok {
+		return hash, nil
+	}
+
+	hash, err := module.Checksum(goModRootPath)
+	if err != nil {
+		return "", err
+	}
+
+	c.moduleHashes[goModRootPath] = hash
+
+	return hash, nil
+}
+
+// isCacheBypassed reports whether any flag which bypasses the cache is set(see cacheBypassingArgs)
+func isCacheBypassed() bool {
+	for _, name := range cacheBypassingArgs {
+		if f := flag.Lookup(name); f != nil && f.Value.String() == "true" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// optionsFingerprint returns all the flags which affect the result of revising
+func optionsFingerprint() string {
+	var b strings.Builder
+	flag.VisitAll(func(f *flag.Flag) {
+		if _, ok := cacheIgnoredArgs[f.Name]; ok {
+			return
+		}
+
+		b.WriteString(fmt.Sprintf("%s=%s\n", f.Name, f.Value.String()))
+	})
+
+	return b.String()
+}
+
+// toolVersion returns the version of the build. Local builds don't have tag and commit, so the hash of the binary
+// is used instead.
+func toolVersion() (string, error) {
+	if Tag != "" || Commit != "" {
+		return Tag + "@" + Commit, nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to determine version")
+	}
+
+	data, err := ioutil.ReadFile(exe)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to determine version")
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
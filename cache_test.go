@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/psawicki5/goimports-reviser/v2/reviser"
+)
+
+// setFlag sets the value of the flag, the value is restored once the test finishes
+func setFlag(t *testing.T, name, value string) {
+	t.Helper()
+
+	f := flag.Lookup(name)
+	require.NotNil(t, f)
+
+	prev := f.Value.String()
+	require.NoError(t, flag.Set(name, value))
+	t.Cleanup(func() { _ = flag.Set(name, prev) })
+}
+
+func TestOptionsFingerprint(t *testing.T) {
+	fingerprint := optionsFingerprint()
+
+	setFlag(t, timeoutArg, "10s")
+	setFlag(t, includeGeneratedArg, "true")
+	setFlag(t, concurrencyArg, "3")
+	assert.Equal(t, fingerprint, optionsFingerprint())
+
+	setFlag(t, removeUnusedImportsArg, "true")
+	assert.NotEqual(t, fingerprint, optionsFingerprint())
+}
+
+func TestIsCacheBypassed(t *testing.T) {
+	setFlag(t, removeUnusedImportsArg, "true")
+	assert.False(t, isCacheBypassed())
+
+	for _, name := range []string{addMissingImportsArg, resolveConflictsArg, fixAliasesArg, rmDotImportsArg} {
+		t.Run(name, func(t *testing.T) {
+			setFlag(t, name, "true")
+			assert.True(t, isCacheBypassed())
+		})
+	}
+}
+
+func TestReviseFile_CachedNotes(t *testing.T) {
+	dir := t.TempDir()
+	setFlag(t, cacheDirArg, filepath.Join(dir, "cache"))
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/a\n\ngo 1.17\n"), 0644))
+
+	filePath := filepath.Join(dir, "a.go")
+	require.NoError(t, ioutil.WriteFile(filePath, []byte(`package a
+
+import (
+	str_conv "strconv"
+)
+
+var _ = str_conv.Itoa
+`), 0644))
+
+	r, err := reviser.New(reviser.Config{ProjectName: "example.com/a", Options: reviser.Options{reviser.OptionLintAliases}})
+	require.NoError(t, err)
+
+	fc, err := newFileCache()
+	require.NoError(t, err)
+
+	var notes [][]string
+	for i := 0; i < 2; i++ {
+		result := reviseFile(context.Background(), r, filePath, fc)
+		require.NoError(t, result.err)
+		assert.False(t, result.hasChange)
+
+		var runNotes []string
+		for _, note := range result.notes {
+			runNotes = append(runNotes, note.String())
+		}
+
+		notes = append(notes, runNotes)
+	}
+
+	require.Len(t, notes[0], 1)
+	assert.Equal(t, notes[0], notes[1])
+}
@@ -28,6 +28,9 @@
 //
 // If you need to set package names explicitly(in import declaration), you can use additional option `-set-alias`.
 //
+// Several files or packages can be revised in parallel:
+//	goimports-reviser -rm-unused -j 4 ./...
+//
 // More:
 //
 // 	goimports-reviser -h
@@ -1,15 +1,20 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
+	"os/signal"
+	"runtime"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/pkg/errors"
-	"github.com/psawicki5/goimports-reviser/v2/pkg/module"
+	"github.com/psawicki5/goimports-reviser/v2/pkg/cache"
 	"github.com/psawicki5/goimports-reviser/v2/reviser"
 )
 
@@ -22,8 +27,34 @@ const (
 	localPkgPrefixesArg    = "local"
 	outputArg              = "output"
 	formatArg              = "format"
+	concurrencyArg         = "concurrency"
+	concurrencyShortArg    = "j"
+	useCacheArg            = "cache"
+	cacheDirArg            = "cache-dir"
+	timeoutArg             = "timeout"
+	floatingCommentsArg    = "floating-comments"
+	addMissingImportsArg   = "add-missing"
+	rmRedundantAliasesArg  = "rm-redundant-aliases"
+	aliasMapArg            = "alias-map"
+	lintAliasesArg         = "lint-aliases"
+	fixAliasesArg          = "fix-aliases"
+	resolveConflictsArg    = "resolve-conflicts"
+	renameStdArg           = "rename-std"
+	rmDotImportsArg        = "rm-dot-imports"
+	dotImportsAllowedArg   = "dot-imports-allowed"
+	importsOrderArg        = "imports-order"
+	generatedOrderArg      = "generated-imports-order"
+	includeGeneratedArg    = "include-generated"
+	keepBlankImportsArg    = "keep-blank-imports-in-place"
+	preserveSubGroupsArg   = "preserve-sub-groups"
 )
 
+var floatingCommentsPolicies = map[string]reviser.FloatingCommentsPolicy{
+	"attach": reviser.FloatingCommentsAttach,
+	"group":  reviser.FloatingCommentsGroupTop,
+	"block":  reviser.FloatingCommentsBlockTop,
+}
+
 // Project build specific vars
 var (
 	Tag       string
@@ -35,16 +66,34 @@ var (
 	shouldRemoveUnusedImports *bool
 	shouldSetAlias            *bool
 	shouldFormat              *bool
+	shouldUseCache            *bool
+	shouldAddMissingImports   *bool
+	shouldRmRedundantAliases  *bool
+	shouldLintAliases         *bool
+	shouldFixAliases          *bool
+	shouldResolveConflicts    *bool
+	shouldRenameStd           *bool
+	shouldRmDotImports        *bool
+	shouldIncludeGenerated    *bool
+	shouldKeepBlankImports    *bool
+	shouldPreserveSubGroups   *bool
 )
 
-var projectName, filePath, localPkgPrefixes, output string
+var projectName, filePath, localPkgPrefixes, output, cacheDir, floatingComments, aliasMap, dotImportsAllowed string
+
+var importsOrder, generatedImportsOrder string
+
+var concurrency int
+
+var timeout time.Duration
 
 func init() {
 	flag.StringVar(
 		&filePath,
 		filePathArg,
 		"",
-		"File path to fix imports(ex.: ./reviser/reviser.go). Required parameter.",
+		"File path to fix imports(ex.: ./reviser/reviser.go). "+
+			"Required parameter if no paths are passed as arguments.",
 	)
 
 	flag.StringVar(
@@ -81,12 +130,162 @@ func init() {
 			"In this case import will be set as 'pg \"github.com/go-pg/pg/v9\"'. Optional parameter.",
 	)
 
+	shouldRmRedundantAliases = flag.Bool(
+		rmRedundantAliasesArg,
+		false,
+		"Remove aliases which are equal to names of imported packages(ex.: 'errors \"github.com/pkg/errors\"'). "+
+			"With -set-alias, aliases of versioned packages are kept. Optional parameter.",
+	)
+
+	shouldLintAliases = flag.Bool(
+		lintAliasesArg,
+		false,
+		"Report aliases which are not lowercase, contain underscores or shadow std packages or predeclared identifiers. "+
+			"Optional parameter.",
+	)
+
+	shouldFixAliases = flag.Bool(
+		fixAliasesArg,
+		false,
+		fmt.Sprintf("Replace aliases which are reported by -%s with compliant ones. Optional parameter.", lintAliasesArg),
+	)
+
+	shouldResolveConflicts = flag.Bool(
+		resolveConflictsArg,
+		false,
+		"Set aliases for imports of different paths with the same name(ex.: 'pkgerrors \"github.com/pkg/errors\"', "+
+			"if \"errors\" is imported too) and rename their usages. Std imports keep their names. Optional parameter.",
+	)
+
+	shouldRenameStd = flag.Bool(
+		renameStdArg,
+		false,
+		fmt.Sprintf("Allow -%s to set aliases for std imports too. Optional parameter.", resolveConflictsArg),
+	)
+
+	shouldRmDotImports = flag.Bool(
+		rmDotImportsArg,
+		false,
+		"Replace dot imports with named imports and qualify identifiers of the imported packages. Optional parameter.",
+	)
+
+	flag.StringVar(
+		&dotImportsAllowed,
+		dotImportsAllowedArg,
+		"",
+		fmt.Sprintf("Patterns of names of files(ex.: '*_test.go'), where dot imports are kept by -%s. ", rmDotImportsArg)+
+			"Values should be comma-separated. Optional parameter.",
+	)
+
+	flag.StringVar(
+		&aliasMap,
+		aliasMapArg,
+		"",
+		"Canonical aliases for import paths, which are set for imports and their usages(ex.: "+
+			"'k8s.io/apimachinery/pkg/apis/meta/v1=metav1,k8s.io/api/*/v1=${group}v1'). "+
+			"Values should be comma-separated. Optional parameter.",
+	)
+
+	flag.StringVar(
+		&importsOrder,
+		importsOrderArg,
+		"std,local,project,general",
+		"Order of groups of imports. Values should be comma-separated, matchers of a single group are joined with "+
+			`"+" and can be followed by the sub-group strategy of the group after "@" and by the sort strategy of the `+
+			`group after ":"(ex.: 'std,general+project@org:natural'). `+
+			"Matchers: std, local, project, general, blank(blank imports in the order of the code). "+
+			"Sort strategies: path(default), alias, natural, case-insensitive, source. "+
+			"Sub-group strategies: module(modules of go.mod), host, org. Optional parameter.",
+	)
+
+	shouldKeepBlankImports = flag.Bool(
+		keepBlankImportsArg,
+		false,
+		"Keep blank imports(ex.: '_ \"github.com/lib/pq\"') in their places within their groups. Optional parameter.",
+	)
+
+	shouldPreserveSubGroups = flag.Bool(
+		preserveSubGroupsArg,
+		false,
+		"Keep runs of imports separated by blank lines as sub-groups of their groups, imports are sorted within the runs. "+
+			"Optional parameter.",
+	)
+
+	flag.StringVar(
+		&generatedImportsOrder,
+		generatedOrderArg,
+		"",
+		fmt.Sprintf("Order of groups of imports of generated files, the same as -%s by default. ", importsOrderArg)+
+			"Optional parameter.",
+	)
+
+	shouldIncludeGenerated = flag.Bool(
+		includeGeneratedArg,
+		false,
+		"Revise generated files(with \"// Code generated ... DO NOT EDIT.\" header) found in directories. "+
+			"Optional parameter.",
+	)
+
+	shouldAddMissingImports = flag.Bool(
+		addMissingImportsArg,
+		false,
+		"Add imports for unresolved package qualifiers(ex.: \"strings\" for strings.ToUpper). "+
+			"Packages are searched in std, the current module and its requirements. Optional parameter.",
+	)
+
 	shouldFormat = flag.Bool(
 		formatArg,
 		false,
 		"Option will perform additional formatting. Optional parameter.",
 	)
 
+	flag.IntVar(
+		&concurrency,
+		concurrencyArg,
+		runtime.GOMAXPROCS(0),
+		"Number of files to be processed in parallel. Optional parameter.",
+	)
+
+	flag.IntVar(
+		&concurrency,
+		concurrencyShortArg,
+		runtime.GOMAXPROCS(0),
+		fmt.Sprintf("Shorthand for -%s.", concurrencyArg),
+	)
+
+	flag.StringVar(
+		&floatingComments,
+		floatingCommentsArg,
+		"attach",
+		`Where to place comments inside of import blocks which don't belong to any import: `+
+			`"attach" - to the following import, "group" - at the top of the group, "block" - at the top of the block. `+
+			"Optional parameter.",
+	)
+
+	flag.DurationVar(
+		&timeout,
+		timeoutArg,
+		0,
+		"Maximum time to revise a single file(ex.: 30s). Zero means no limit. Optional parameter.",
+	)
+
+	shouldUseCache = flag.Bool(
+		useCacheArg,
+		false,
+		"Skip files which are known to be already conforming(unchanged since the last run with the same options). "+
+			fmt.Sprintf("It's ignored with -%s, -%s, -%s and -%s. Optional parameter.",
+				addMissingImportsArg, resolveConflictsArg, fixAliasesArg, rmDotImportsArg),
+	)
+
+	flag.StringVar(
+		&cacheDir,
+		cacheDirArg,
+		"",
+		"Cache directory(default is a directory inside of the user cache dir). "+
+			fmt.Sprintf(`Use "%s %s" or "%s %s" commands to manage the cache. Optional parameter.`,
+				cacheCmd, cacheStatsCmd, cacheCmd, cacheCleanCmd),
+	)
+
 	if Tag != "" {
 		shouldShowVersion = flag.Bool(
 			versionArg,
@@ -96,8 +295,35 @@ func init() {
 	}
 }
 
+// splitList splits the comma-separated list(empty values are skipped)
+func splitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+// parseAliasMap parses comma-separated pairs of import paths and aliases(ex.: `k8s.io/api/core/v1=corev1`)
+func parseAliasMap(value string) (reviser.AliasPolicy, error) {
+	policy := reviser.AliasPolicy{}
+	for _, pair := range splitList(value) {
+		values := strings.SplitN(pair, "=", 2)
+		if len(values) != 2 || values[0] == "" || values[1] == "" {
+			return nil, errors.Errorf(`expected "path=alias", got "%s"`, pair)
+		}
+
+		policy[values[0]] = values[1]
+	}
+
+	return policy, nil
+}
+
 func printUsage() {
-	if _, err := fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0]); err != nil {
+	if _, err := fmt.Fprintf(os.Stderr, "Usage of %s: [flags] [path ...]\n", os.Args[0]); err != nil {
 		log.Fatalf("failed to print usage: %s", err)
 	}
 
@@ -123,19 +349,51 @@ func main() {
 		return
 	}
 
-	if err := validateRequiredParam(filePath); err != nil {
+	if isCacheCommand(flag.Args()) {
+		runCacheCommand(flag.Arg(1))
+		return
+	}
+
+	validateFlags()
+
+	if isAliasesCommand(flag.Args()) {
+		runAliasesCommand(flag.Args()[1:])
+		return
+	}
+
+	paths := flag.Args()
+	if filePath != "" {
+		paths = append([]string{filePath}, paths...)
+	}
+
+	if err := validateRequiredParam(paths); err != nil {
 		fmt.Printf("%s\n\n", err)
 		printUsage()
 		os.Exit(1)
 	}
 
-	projectName, err := determineProjectName(projectName, filePath)
+	files, err := collectFiles(paths, isGeneratedIncluded())
 	if err != nil {
-		fmt.Printf("%s\n\n", err)
-		printUsage()
+		log.Fatalf("failed to collect files: %+v", errors.WithStack(err))
+	}
+
+	r := newReviser(nil)
+
+	var fc *fileCache
+	if shouldUseCache != nil && *shouldUseCache && !isCacheBypassed() {
+		fc, err = newFileCache()
+		if err != nil {
+			log.Fatalf("%+v", errors.WithStack(err))
+		}
+	}
+
+	if !reviseAll(files, r, fc) {
 		os.Exit(1)
 	}
+}
 
+// newReviser creates the reviser configured by the flags. The policy is added to the alias policy set by the flags.
+func newReviser(policy reviser.AliasPolicy) *reviser.Reviser {
 	var options reviser.Options
 	if shouldRemoveUnusedImports != nil && *shouldRemoveUnusedImports {
 		options = append(options, reviser.OptionRemoveUnusedImports)
@@ -145,51 +403,242 @@ func main() {
 		options = append(options, reviser.OptionUseAliasForVersionSuffix)
 	}
 
+	if shouldRmRedundantAliases != nil && *shouldRmRedundantAliases {
+		options = append(options, reviser.OptionRemoveRedundantAliases)
+	}
+
+	if shouldLintAliases != nil && *shouldLintAliases {
+		options = append(options, reviser.OptionLintAliases)
+	}
+
+	if shouldFixAliases != nil && *shouldFixAliases {
+		options = append(options, reviser.OptionFixAliases)
+	}
+
+	if shouldResolveConflicts != nil && *shouldResolveConflicts {
+		options = append(options, reviser.OptionResolveNameConflicts)
+	}
+
+	if shouldRenameStd != nil && *shouldRenameStd {
+		options = append(options, reviser.OptionRenameStdImports)
+	}
+
+	if shouldRmDotImports != nil && *shouldRmDotImports {
+		options = append(options, reviser.OptionRemoveDotImports)
+	}
+
+	if shouldKeepBlankImports != nil && *shouldKeepBlankImports {
+		options = append(options, reviser.OptionKeepBlankImportsInPlace)
+	}
+
+	if shouldPreserveSubGroups != nil && *shouldPreserveSubGroups {
+		options = append(options, reviser.OptionPreserveSubGroups)
+	}
+
 	if shouldFormat != nil && *shouldFormat {
 		options = append(options, reviser.OptionFormat)
 	}
 
-	formattedOutput, hasChange, err := reviser.Execute(projectName, filePath, localPkgPrefixes, options...)
+	if shouldAddMissingImports != nil && *shouldAddMissingImports {
+		options = append(options, reviser.OptionAddMissingImports)
+	}
+
+	floatingCommentsPolicy, ok := floatingCommentsPolicies[floatingComments]
+	if !ok {
+		log.Fatalf(`invalid -%s "%s" specified`, floatingCommentsArg, floatingComments)
+	}
+
+	aliasPolicy, err := parseAliasMap(aliasMap)
+	if err != nil {
+		log.Fatalf(`invalid -%s "%s" specified: %s`, aliasMapArg, aliasMap, err)
+	}
+
+	for importPath, alias := range policy {
+		aliasPolicy[importPath] = alias
+	}
+
+	// the index of packages is stored in the cache dir, so it's not built from scratch by every run
+	var indexDir string
+	if shouldAddMissingImports != nil && *shouldAddMissingImports {
+		c, err := openCache()
+		if err != nil {
+			log.Fatalf("%+v", errors.WithStack(err))
+		}
+
+		indexDir = c.IndexDir()
+	}
+
+	r, err := reviser.New(reviser.Config{
+		ProjectName:           projectName,
+		DetectProjectName:     true,
+		LocalPkgPrefixes:      localPkgPrefixes,
+		Options:               options,
+		FloatingComments:      floatingCommentsPolicy,
+		IndexDir:              indexDir,
+		AliasPolicy:           aliasPolicy,
+		DotImportsAllowed:     splitList(dotImportsAllowed),
+		ImportGroups:          reviser.ParseImportGroups(importsOrder),
+		GeneratedImportGroups: reviser.ParseImportGroups(generatedImportsOrder),
+	})
 	if err != nil {
-		log.Fatalf("%+v", errors.WithStack(err))
+		log.Fatal(err)
 	}
 
-	if output == "stdout" {
-		fmt.Print(string(formattedOutput))
-	} else if output == "file" {
-		if !hasChange {
-			return
+	return r
+}
+
+func isGeneratedIncluded() bool {
+	return shouldIncludeGenerated != nil && *shouldIncludeGenerated
+}
+
+// reviseAll revises the files and prints the results. false is returned if any file can't be revised.
+func reviseAll(files []string, r *reviser.Reviser, fc *fileCache) bool {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var (
+		hasErrors    bool
+		skippedFiles int
+	)
+	for result := range reviseFiles(files, concurrency, func(filePath string) *fileResult {
+		return reviseFile(ctx, r, filePath, fc)
+	}) {
+		if result.err != nil {
+			hasErrors = true
+
+			if ctx.Err() != nil && errors.Is(result.err, context.Canceled) {
+				skippedFiles++
+				continue
+			}
+
+			printError(result.filePath, result.err)
+
+			continue
 		}
 
-		if err := ioutil.WriteFile(filePath, formattedOutput, 0644); err != nil {
-			log.Fatalf("failed to write fixed result to file(%s): %+v", filePath, errors.WithStack(err))
+		for _, note := range result.notes {
+			fmt.Fprintln(os.Stderr, note)
+		}
+
+		if output == "stdout" {
+			fmt.Print(string(result.content))
 		}
-	} else {
-		log.Fatalf(`invalid output "%s" specified`, output)
 	}
+
+	if skippedFiles > 0 {
+		log.Printf("interrupted: %d file(s) were not revised", skippedFiles)
+	}
+
+	return !hasErrors
 }
 
-func determineProjectName(projectName, filePath string) (string, error) {
-	if projectName == "" {
-		projectRootPath, err := module.GoModRootPath(filePath)
+// reviseFile fixes imports of the file and writes the result back to the file in case of "file" output. Files which
+// are known to be conforming by the cache(if it's set) are not revised.
+func reviseFile(ctx context.Context, r *reviser.Reviser, filePath string, fc *fileCache) *fileResult {
+	result := &fileResult{filePath: filePath}
+
+	// the run is interrupted, so the rest of files are skipped
+	if err := ctx.Err(); err != nil {
+		result.err = err
+		return result
+	}
+
+	originalContent, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		result.err = errors.WithStack(err)
+		return result
+	}
+
+	var entry *cache.Entry
+	if fc != nil {
+		entry, err = fc.entry(filePath, originalContent)
 		if err != nil {
-			return "", err
+			result.err = errors.WithStack(err)
+			return result
 		}
 
-		moduleName, err := module.Name(projectRootPath)
-		if err != nil {
-			return "", err
+		if fc.IsConforming(filePath, entry) {
+			result.content = originalContent
+			return result
+		}
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	revised, err := r.ReviseSource(ctx, filePath, originalContent)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			result.err = errors.Errorf("timed out after %s", timeout)
+			return result
 		}
 
-		return moduleName, nil
+		result.err = errors.WithStack(err)
+		return result
 	}
 
-	return projectName, nil
+	result.content, result.hasChange, result.notes = revised.Content, revised.HasChange, revised.Notes
+
+	if output == "file" && result.hasChange {
+		if err := ioutil.WriteFile(filePath, result.content, 0644); err != nil {
+			result.err = errors.Wrapf(err, "failed to write fixed result to file(%s)", filePath)
+			return result
+		}
+	}
+
+	// the revised content is conforming, but it's known to be on the disk only if there was nothing to change or
+	// it has been written. Files with notes are revised again, so the notes are reported by every run.
+	if fc != nil && (!result.hasChange || output == "file") && len(result.notes) == 0 {
+		entry.ContentHash = cache.Hash(result.content)
+		if err := fc.MarkConforming(filePath, entry); err != nil {
+			log.Printf("%s: failed to update cache: %s", filePath, err)
+		}
+	}
+
+	return result
+}
+
+// printError prints errors of revising in the same way as the go toolchain does("file:line:col: message").
+// Unexpected errors are printed with their stack.
+func printError(filePath string, err error) {
+	var (
+		parseErr       *reviser.ParseError
+		packageLoadErr *reviser.PackageLoadError
+		formatErr      *reviser.FormatError
+		configErr      *reviser.ConfigError
+	)
+
+	// errors of the reviser have positions or file paths in their messages, so stack traces are not printed
+	if errors.As(err, &parseErr) || errors.As(err, &packageLoadErr) ||
+		errors.As(err, &formatErr) || errors.As(err, &configErr) {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	log.Printf("%s: %+v", filePath, err)
+}
+
+// validateFlags exits if values of the flags are invalid, so subcommands don't fail after files are processed
+func validateFlags() {
+	if output != "stdout" && output != "file" {
+		log.Fatalf(`invalid output "%s" specified`, output)
+	}
+
+	if _, ok := floatingCommentsPolicies[floatingComments]; !ok {
+		log.Fatalf(`invalid -%s "%s" specified`, floatingCommentsArg, floatingComments)
+	}
+
+	if _, err := parseAliasMap(aliasMap); err != nil {
+		log.Fatalf(`invalid -%s "%s" specified: %s`, aliasMapArg, aliasMap, err)
+	}
 }
 
-func validateRequiredParam(filePath string) error {
-	if filePath == "" {
-		return errors.Errorf("-%s should be set", filePathArg)
+func validateRequiredParam(paths []string) error {
+	if len(paths) == 0 {
+		return errors.Errorf("-%s or at least one path should be set", filePathArg)
 	}
 
 	return nil
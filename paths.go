@@ -0,0 +1,102 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/psawicki5/goimports-reviser/v2/reviser"
+)
+
+const recursivePathSuffix = "..."
+
+// collectFiles expands the passed paths to the list of go files. Path can be a file, a directory(only files of this
+// directory will be used) or a directory with "/..." suffix(files of all nested packages will be used). Files are
+// returned in the order of the passed paths without duplicates. Generated files of directories are skipped, unless
+// includeGenerated is set.
+func collectFiles(paths []string, includeGenerated bool) ([]string, error) {
+	var files []string
+	seen := map[string]struct{}{}
+
+	add := func(filePath string) {
+		filePath = filepath.Clean(filePath)
+		if _, ok := seen[filePath]; ok {
+			return
+		}
+
+		seen[filePath] = struct{}{}
+		files = append(files, filePath)
+	}
+
+	for _, p := range paths {
+		if p == recursivePathSuffix || strings.HasSuffix(p, "/"+recursivePathSuffix) {
+			root := strings.TrimSuffix(strings.TrimSuffix(p, recursivePathSuffix), "/")
+			if root == "" {
+				root = "."
+			}
+
+			if err := walkGoFiles(root, true, includeGenerated, add); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		fi, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+
+		if !fi.IsDir() {
+			add(p)
+			continue
+		}
+
+		if err := walkGoFiles(p, false, includeGenerated, add); err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+func walkGoFiles(root string, recursive, includeGenerated bool, fn func(filePath string)) error {
+	return filepath.Walk(root, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if filePath == root {
+				return nil
+			}
+
+			if !recursive || isIgnoredDir(info.Name()) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !strings.HasSuffix(info.Name(), ".go") {
+			return nil
+		}
+
+		// files which can't be parsed are collected, so their errors are reported by revising
+		if isGenerated, err := reviser.IsGeneratedFile(filePath); !includeGenerated && err == nil && isGenerated {
+			return nil
+		}
+
+		fn(filePath)
+
+		return nil
+	})
+}
+
+// isIgnoredDir follows the rules of the go tool: such directories are not a part of the build
+func isIgnoredDir(name string) bool {
+	return name == "vendor" ||
+		name == "testdata" ||
+		strings.HasPrefix(name, ".") ||
+		strings.HasPrefix(name, "_")
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectFiles(t *testing.T) {
+	root, err := ioutil.TempDir("", "goimports-reviser")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	files := map[string]string{
+		"a.go":                  "package a\n",
+		"b.go":                  "package a\n",
+		"README.md":             "# a\n",
+		"generated.go":          "// Code generated by stringer. DO NOT EDIT.\n\npackage a\n",
+		"nested/c.go":           "package nested\n",
+		"nested/deep/d.go":      "package deep\n",
+		"vendor/e/e.go":         "package e\n",
+		"testdata/f.go":         "package testdata\n",
+		"nested/testdata/g.go":  "package testdata\n",
+		".hidden/h.go":          "package hidden\n",
+		"_skipped/i.go":         "package skipped\n",
+		"nested/vendor/j/j.go":  "package j\n",
+		"nested/generated.go":   "// Code generated by mockgen. DO NOT EDIT.\n\npackage nested\n",
+		"nested/deep/broken.go": "package",
+	}
+	for name, content := range files {
+		filePath := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		require.NoError(t, ioutil.WriteFile(filePath, []byte(content), 0644))
+	}
+
+	join := func(names ...string) []string {
+		paths := make([]string, 0, len(names))
+		for _, name := range names {
+			paths = append(paths, filepath.Join(root, name))
+		}
+
+		return paths
+	}
+
+	tests := []struct {
+		name             string
+		paths            []string
+		includeGenerated bool
+		want             []string
+	}{
+		{
+			name:  "success with dir",
+			paths: join(""),
+			want:  join("a.go", "b.go"),
+		},
+		{
+			name:  "success with recursive dir",
+			paths: []string{root + "/..."},
+			want:  join("a.go", "b.go", "nested/c.go", "nested/deep/broken.go", "nested/deep/d.go"),
+		},
+		{
+			name:             "success with generated files",
+			paths:            []string{root + "/..."},
+			includeGenerated: true,
+			want: join(
+				"a.go", "b.go", "generated.go", "nested/c.go", "nested/deep/broken.go", "nested/deep/d.go",
+				"nested/generated.go",
+			),
+		},
+		{
+			name:  "success with files in the order of paths without duplicates",
+			paths: append(join("nested/c.go", "testdata/f.go", "generated.go"), root+"/...", root),
+			want: join(
+				"nested/c.go", "testdata/f.go", "generated.go", "a.go", "b.go", "nested/deep/broken.go",
+				"nested/deep/d.go",
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := collectFiles(tt.paths, tt.includeGenerated)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCollectFiles_NotExisting(t *testing.T) {
+	_, err := collectFiles([]string{"not_existing.go"}, false)
+	assert.True(t, os.IsNotExist(err))
+}
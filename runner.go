@@ -0,0 +1,66 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/psawicki5/goimports-reviser/v2/reviser"
+)
+
+type fileResult struct {
+	filePath  string
+	content   []byte
+	hasChange bool
+	notes     []*reviser.Note
+	err       error
+}
+
+// reviseFiles runs reviseFn for the files using a pool of concurrency workers. Results are sent to the returned
+// channel in the order of the files, regardless of the order in which they were processed.
+func reviseFiles(files []string, concurrency int, reviseFn func(filePath string) *fileResult) <-chan *fileResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	if concurrency > len(files) {
+		concurrency = len(files)
+	}
+
+	pending := make([]chan *fileResult, len(files))
+	for i := range pending {
+		pending[i] = make(chan *fileResult, 1)
+	}
+
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+
+		for i := range files {
+			jobs <- i
+		}
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for w := 0; w < concurrency; w++ {
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				pending[i] <- reviseFn(files[i])
+			}
+		}()
+	}
+
+	results := make(chan *fileResult)
+	go func() {
+		defer close(results)
+
+		for _, ch := range pending {
+			results <- <-ch
+		}
+
+		wg.Wait()
+	}()
+
+	return results
+}
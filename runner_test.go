@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviseFiles(t *testing.T) {
+	files := make([]string, 20)
+	for i := range files {
+		files[i] = strconv.Itoa(i) + ".go"
+	}
+
+	tests := []struct {
+		name        string
+		concurrency int
+	}{
+		{name: "success with single worker", concurrency: 1},
+		{name: "success with several workers", concurrency: 4},
+		{name: "success with more workers than files", concurrency: 100},
+		{name: "success with invalid concurrency", concurrency: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				stdout bytes.Buffer
+				failed []string
+				want   bytes.Buffer
+			)
+			for result := range reviseFiles(files, tt.concurrency, func(filePath string) *fileResult {
+				i, _ := strconv.Atoi(filePath[:len(filePath)-len(".go")])
+				// later files finish first, so results are reordered
+				time.Sleep(time.Duration(len(files)-i) * time.Millisecond)
+
+				if i%7 == 3 {
+					return &fileResult{filePath: filePath, err: errors.New("failed")}
+				}
+
+				return &fileResult{filePath: filePath, content: []byte(fmt.Sprintf("// %s\n", filePath))}
+			}) {
+				if result.err != nil {
+					failed = append(failed, result.filePath)
+					continue
+				}
+
+				stdout.Write(result.content)
+			}
+
+			for i, filePath := range files {
+				if i%7 != 3 {
+					fmt.Fprintf(&want, "// %s\n", filePath)
+				}
+			}
+
+			assert.Equal(t, want.String(), stdout.String())
+			assert.Equal(t, []string{"3.go", "10.go", "17.go"}, failed)
+		})
+	}
+}
+
+func TestReviseFiles_NoFiles(t *testing.T) {
+	var results []*fileResult
+	for result := range reviseFiles(nil, 4, func(filePath string) *fileResult {
+		return &fileResult{filePath: filePath}
+	}) {
+		results = append(results, result)
+	}
+
+	assert.Empty(t, results)
+}
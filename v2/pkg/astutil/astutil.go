@@ -1,9 +1,15 @@
 package astutil
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"go/ast"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
 	"strings"
 
 	"golang.org/x/tools/go/packages"
@@ -65,10 +71,17 @@ func UsesImport(f *ast.File, packageImports PackageImports, importPath string) b
 // LoadPackageDependencies will return all package's imports with it names:
 // 		key - package(ex.: github/pkg/errors), value - name(ex.: errors)
 func LoadPackageDependencies(dir, buildTag string) (PackageImports, error) {
+	return LoadPackageDependenciesContext(context.Background(), dir, buildTag)
+}
+
+// LoadPackageDependenciesContext is like LoadPackageDependencies, but loading(it runs `go list` under the hood) will
+// be interrupted once the ctx is done
+func LoadPackageDependenciesContext(ctx context.Context, dir, buildTag string) (PackageImports, error) {
 	cfg := &packages.Config{
-		Dir:   dir,
-		Tests: true,
-		Mode:  packages.NeedName | packages.NeedImports,
+		Context: ctx,
+		Dir:     dir,
+		Tests:   true,
+		Mode:    packages.NeedName | packages.NeedImports,
 	}
 
 	if buildTag != "" {
@@ -76,6 +89,10 @@ func LoadPackageDependencies(dir, buildTag string) (PackageImports, error) {
 	}
 
 	pkgs, err := packages.Load(cfg)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return PackageImports{}, ctxErr
+	}
+
 	if err != nil {
 		return PackageImports{}, err
 	}
@@ -95,6 +112,99 @@ func LoadPackageDependencies(dir, buildTag string) (PackageImports, error) {
 	return result, nil
 }
 
+// LoadPackageNamesContext will return names of the packages by their import paths. Packages which can't be loaded
+// are skipped.
+func LoadPackageNamesContext(ctx context.Context, dir, buildTag string, importPaths ...string) (PackageImports, error) {
+	result := PackageImports{}
+	if len(importPaths) == 0 {
+		return result, nil
+	}
+
+	cfg := &packages.Config{
+		Context: ctx,
+		Dir:     dir,
+		Mode:    packages.NeedName,
+	}
+
+	if buildTag != "" {
+		cfg.BuildFlags = []string{fmt.Sprintf(`-tags=%s`, buildTag)}
+	}
+
+	pkgs, err := packages.Load(cfg, importPaths...)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return PackageImports{}, ctxErr
+	}
+
+	if err != nil {
+		return PackageImports{}, err
+	}
+
+	for _, pkg := range pkgs {
+		if pkg.Name == "" {
+			continue
+		}
+
+		result[pkg.PkgPath] = pkg.Name
+	}
+
+	return result, nil
+}
+
+// LoadPackageTypesContext returns type information of the packages by their import paths, which are resolved
+// relative to the dir. Types are read from export data, which is built(and cached) by the go command, so sources of
+// dependencies are not type checked. Packages which can't be loaded are skipped.
+func LoadPackageTypesContext(
+	ctx context.Context,
+	dir, buildTag string,
+	importPaths ...string,
+) (map[string]*types.Package, error) {
+	result := map[string]*types.Package{}
+	if len(importPaths) == 0 {
+		return result, nil
+	}
+
+	cfg := &packages.Config{
+		Context: ctx,
+		Dir:     dir,
+		Mode:    packages.NeedName | packages.NeedImports | packages.NeedExportsFile,
+	}
+
+	if buildTag != "" {
+		cfg.BuildFlags = []string{fmt.Sprintf(`-tags=%s`, buildTag)}
+	}
+
+	pkgs, err := packages.Load(cfg, importPaths...)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	exportFiles := make(map[string]string, len(pkgs))
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) == 0 && pkg.ExportFile != "" {
+			exportFiles[pkg.PkgPath] = pkg.ExportFile
+		}
+	}
+
+	imp := importer.ForCompiler(token.NewFileSet(), "gc", func(importPath string) (io.ReadCloser, error) {
+		return os.Open(exportFiles[importPath])
+	})
+
+	for importPath := range exportFiles {
+		pkg, err := imp.Import(importPath)
+		if err != nil {
+			continue
+		}
+
+		result[importPath] = pkg
+	}
+
+	return result, nil
+}
+
 // ParseBuildTag parse `// +build ...` on a first line of *ast.File
 func ParseBuildTag(f *ast.File) string {
 	comments := f.Comments
@@ -1,6 +1,8 @@
 package astutil
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"go/parser"
 	"go/token"
@@ -226,3 +228,30 @@ func TestLoadPackageDeps(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadPackageDependenciesContext_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := LoadPackageDependenciesContext(ctx, "./testdata/", "")
+	require.Error(t, err)
+	require.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestLoadPackageTypesContext(t *testing.T) {
+	got, err := LoadPackageTypesContext(context.Background(), "./testdata/", "", "strings", "not/existing")
+	require.NoError(t, err)
+
+	require.Len(t, got, 1)
+	require.True(t, got["strings"].Complete())
+	require.NotNil(t, got["strings"].Scope().Lookup("ToUpper"))
+}
+
+func TestLoadPackageTypesContext_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := LoadPackageTypesContext(ctx, "./testdata/", "", "strings")
+	require.Error(t, err)
+	require.True(t, errors.Is(err, context.Canceled))
+}
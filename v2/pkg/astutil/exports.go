@@ -0,0 +1,229 @@
+package astutil
+
+import (
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PackageExports is a package with its exported identifiers
+type PackageExports struct {
+	ImportPath string
+	Name       string
+	Exports    map[string]struct{}
+}
+
+// HasExports reports whether the package exports all the identifiers
+func (p *PackageExports) HasExports(names ...string) bool {
+	for _, name := range names {
+		if _, ok := p.Exports[name]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// LoadPackageExports parses go files of the package in the dir(test files and files which don't match the current
+// build context are skipped) and returns exported identifiers of the package. nil is returned if there are no such
+// files in the dir.
+func LoadPackageExports(importPath, dir string) (*PackageExports, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *PackageExports
+
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		if ok, err := build.Default.MatchFile(dir, name); err != nil || !ok {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			continue
+		}
+
+		if result == nil {
+			result = &PackageExports{ImportPath: importPath, Name: f.Name.Name, Exports: map[string]struct{}{}}
+		}
+
+		// files of other packages(ex.: "documentation") are skipped
+		if f.Name.Name != result.Name {
+			continue
+		}
+
+		for name := range DeclaredNames(f) {
+			if ast.IsExported(name) {
+				result.Exports[name] = struct{}{}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// DeclaredNames returns names of top-level declarations of the file(methods are skipped)
+func DeclaredNames(f *ast.File) map[string]struct{} {
+	names := map[string]struct{}{}
+
+	for _, decl := range f.Decls {
+		switch dd := decl.(type) {
+		case *ast.FuncDecl:
+			if dd.Recv == nil {
+				names[dd.Name.Name] = struct{}{}
+			}
+		case *ast.GenDecl:
+			for _, spec := range dd.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					names[spec.Name.Name] = struct{}{}
+				case *ast.ValueSpec:
+					for _, ident := range spec.Names {
+						names[ident.Name] = struct{}{}
+					}
+				}
+			}
+		}
+	}
+
+	return names
+}
+
+// UnresolvedQualifiers returns selectors of the file, which are qualified by unresolved identifiers(ex.: `strings`
+// in `strings.ToUpper`, if "strings" isn't imported), by the qualifiers. The identifiers can also be declared in other
+// files of the package, so they should be checked separately.
+func UnresolvedQualifiers(f *ast.File) map[string][]*ast.SelectorExpr {
+	importNames := make(map[string]struct{}, len(f.Imports))
+	for _, spec := range f.Imports {
+		if spec.Name != nil {
+			importNames[spec.Name.Name] = struct{}{}
+			continue
+		}
+
+		if importPath, err := strconv.Unquote(spec.Path.Value); err == nil {
+			importNames[ImportPathToAssumedName(importPath)] = struct{}{}
+		}
+	}
+
+	result := map[string][]*ast.SelectorExpr{}
+	ast.Inspect(f, func(node ast.Node) bool {
+		sel, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		ident, ok := sel.X.(*ast.Ident)
+		if !ok || ident.Obj != nil || ident.Name == "_" {
+			return true
+		}
+
+		if _, ok := importNames[ident.Name]; ok {
+			return true
+		}
+
+		result[ident.Name] = append(result[ident.Name], sel)
+
+		return true
+	})
+
+	return result
+}
+
+// ImportPathToAssumedName returns the name of the package which is assumed by the import path(ex.: "pg" for
+// "github.com/go-pg/pg/v9" or "yaml" for "gopkg.in/yaml.v3") in the same way as goimports does
+func ImportPathToAssumedName(importPath string) string {
+	base := importPath
+	if idx := strings.LastIndex(base, "/"); idx >= 0 {
+		base = base[idx+1:]
+	}
+
+	if isVersionSuffix(base) {
+		base = strings.TrimSuffix(importPath, "/"+base)
+		if idx := strings.LastIndex(base, "/"); idx >= 0 {
+			base = base[idx+1:]
+		}
+	}
+
+	base = strings.TrimPrefix(base, "go-")
+
+	if idx := strings.IndexFunc(base, notIdentifier); idx >= 0 {
+		base = base[:idx]
+	}
+
+	return base
+}
+
+func isVersionSuffix(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+
+	for _, r := range s[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func notIdentifier(r rune) bool {
+	return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '_' || r >= 0x80)
+}
+
+// RenameQualifier replaces the qualifier of selectors(ex.: `pkgerrors` in `pkgerrors.Wrap`) with the name. Only
+// unresolved qualifiers(which refer to imports) are replaced. The number of replaced qualifiers is returned.
+func RenameQualifier(f *ast.File, qualifier, name string) int {
+	var renamed int
+	ast.Inspect(f, func(node ast.Node) bool {
+		sel, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		if ident, ok := sel.X.(*ast.Ident); ok && ident.Obj == nil && ident.Name == qualifier {
+			ident.Name = name
+			renamed++
+		}
+
+		return true
+	})
+
+	return renamed
+}
+
+// IsNameDeclared reports whether the name is declared anywhere in the file(in any scope), so the import with this
+// name could be shadowed
+func IsNameDeclared(f *ast.File, name string) bool {
+	if _, ok := DeclaredNames(f)[name]; ok {
+		return true
+	}
+
+	var declared bool
+	ast.Inspect(f, func(node ast.Node) bool {
+		if declared {
+			return false
+		}
+
+		if ident, ok := node.(*ast.Ident); ok && ident.Name == name && ident.Obj != nil && ident.Obj.Kind != ast.Bad {
+			declared = true
+		}
+
+		return !declared
+	})
+
+	return declared
+}
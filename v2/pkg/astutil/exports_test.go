@@ -0,0 +1,182 @@
+package astutil
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPackageExports(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"lib.go": `package lib
+
+type Client struct{}
+
+func (c *Client) Do() {}
+
+func New() *Client { return nil }
+
+func helper() {}
+
+const (
+	Version = "v1"
+	debug   = false
+)
+
+var Default, Other = New(), New()
+`,
+		"lib_test.go": `package lib
+
+func TestOnly() {}
+`,
+		"ignored.go": `// +build ignore
+
+package main
+
+func Ignored() {}
+`,
+	}
+	for name, content := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	got, err := LoadPackageExports("example.com/lib", dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, &PackageExports{
+		ImportPath: "example.com/lib",
+		Name:       "lib",
+		Exports: map[string]struct{}{
+			"Client":  {},
+			"New":     {},
+			"Version": {},
+			"Default": {},
+			"Other":   {},
+		},
+	}, got)
+	assert.True(t, got.HasExports("New", "Client"))
+	assert.False(t, got.HasExports("New", "Do"))
+
+	empty, err := LoadPackageExports("example.com/empty", t.TempDir())
+	require.NoError(t, err)
+	assert.Nil(t, empty)
+}
+
+func TestUnresolvedQualifiers(t *testing.T) {
+	src := `package main
+
+import (
+	"fmt"
+	pkgerrors "github.com/pkg/errors"
+	"github.com/go-pg/pg/v9"
+)
+
+type config struct{ name string }
+
+func main() {
+	var cfg config
+	fmt.Println(cfg.name, strings.ToUpper("a"), pg.In(nil), pkgerrors.New("a"))
+	_ = strings.TrimSpace(errors.New("b").Error())
+	_ = local.Value
+}
+`
+
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	require.NoError(t, err)
+
+	got := UnresolvedQualifiers(f)
+
+	var qualifiers []string
+	for qualifier := range got {
+		qualifiers = append(qualifiers, qualifier)
+	}
+	sort.Strings(qualifiers)
+
+	assert.Equal(t, []string{"errors", "local", "strings"}, qualifiers)
+	require.Len(t, got["strings"], 2)
+	assert.Equal(t, "ToUpper", got["strings"][0].Sel.Name)
+	assert.Equal(t, "TrimSpace", got["strings"][1].Sel.Name)
+}
+
+func TestImportPathToAssumedName(t *testing.T) {
+	tests := []struct {
+		importPath string
+		want       string
+	}{
+		{importPath: "fmt", want: "fmt"},
+		{importPath: "net/http", want: "http"},
+		{importPath: "github.com/go-pg/pg/v9", want: "pg"},
+		{importPath: "gopkg.in/yaml.v3", want: "yaml"},
+		{importPath: "github.com/mattn/go-sqlite3", want: "sqlite3"},
+		{importPath: "github.com/psawicki5/goimports-reviser", want: "goimports"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.importPath, func(t *testing.T) {
+			assert.Equal(t, tt.want, ImportPathToAssumedName(tt.importPath))
+		})
+	}
+}
+
+func TestRenameQualifier(t *testing.T) {
+	src := `package main
+
+import pkgerrors "github.com/pkg/errors"
+
+func main() {
+	_ = pkgerrors.New("a")
+	_ = pkgerrors.WithStack(nil)
+}
+
+func shadowed(pkgerrors struct{ New func() }) {
+	pkgerrors.New()
+}
+`
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, RenameQualifier(f, "pkgerrors", "errors"))
+	assert.Equal(t, 0, RenameQualifier(f, "pkgerrors", "errors"))
+}
+
+func TestIsNameDeclared(t *testing.T) {
+	src := `package main
+
+type config struct{}
+
+func main() {
+	for i, name := range []string{} {
+		_, _ = i, name
+	}
+}
+`
+
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "config", want: true},
+		{name: "main", want: true},
+		{name: "name", want: true},
+		{name: "errors", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsNameDeclared(f, tt.name))
+		})
+	}
+}
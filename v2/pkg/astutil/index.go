@@ -0,0 +1,273 @@
+package astutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/psawicki5/goimports-reviser/v2/pkg/fsutil"
+)
+
+// indexFormatVersion is changed whenever the format of stored indexes(or the way they are built) is changed, so
+// indexes stored by other versions are rebuilt
+const indexFormatVersion = 1
+
+// IndexRoot is a directory tree of packages to be indexed: std(GOROOT/src), the module or the required module
+type IndexRoot struct {
+	// ImportPath is the import path of Dir. It's empty for std.
+	ImportPath string
+
+	// Dir is the root directory of the tree
+	Dir string
+
+	// Key identifies the content of the tree(ex.: the version of go or the hash of the module from go.sum). If the
+	// key isn't changed since the last scan, the stored packages are used as is. If the key is empty, only
+	// directories which are modified since the last scan are parsed again.
+	Key string
+
+	// SkipInternal skips internal packages, which can't be imported(ex.: internal packages of required modules)
+	SkipInternal bool
+}
+
+// Index keeps exported identifiers of packages of the roots by names of the packages. Main packages are skipped.
+// The index is read-only after it's loaded, so it can be shared between goroutines.
+type Index struct {
+	roots  []*indexedRoot
+	byName map[string][]*PackageExports
+}
+
+// indexedRoot is a scanned root, it's stored as is
+type indexedRoot struct {
+	Version    int               `json:"version"`
+	ImportPath string            `json:"importPath"`
+	Dir        string            `json:"dir"`
+	Key        string            `json:"key"`
+	Packages   []*indexedPackage `json:"packages"`
+
+	isChanged bool
+}
+
+type indexedPackage struct {
+	ImportPath string `json:"importPath"`
+	Dir        string `json:"dir"`
+
+	// ModTime is the latest modification time of the directory and its go files(in nanoseconds)
+	ModTime int64    `json:"modTime"`
+	Name    string   `json:"name,omitempty"`
+	Exports []string `json:"exports,omitempty"`
+}
+
+// LoadIndex scans the roots and returns the index of their packages. If dir isn't empty, the roots scanned and saved
+// before are loaded from it and only changed packages are parsed again. The stored data which can't be read is
+// ignored, so the index is always returned.
+func LoadIndex(dir string, roots ...*IndexRoot) *Index {
+	idx := &Index{byName: map[string][]*PackageExports{}}
+
+	for _, root := range roots {
+		var stored *indexedRoot
+		if dir != "" {
+			stored = readIndexedRoot(indexRootPath(dir, root.Dir))
+		}
+
+		if stored != nil && (stored.Dir != root.Dir || stored.ImportPath != root.ImportPath) {
+			stored = nil
+		}
+
+		indexed := stored
+		if stored == nil || stored.Key == "" || stored.Key != root.Key {
+			indexed = scanIndexRoot(root, stored)
+		}
+
+		idx.roots = append(idx.roots, indexed)
+
+		for _, pkg := range indexed.Packages {
+			if pkg.Name == "" || pkg.Name == "main" {
+				continue
+			}
+
+			exports := &PackageExports{
+				ImportPath: pkg.ImportPath,
+				Name:       pkg.Name,
+				Exports:    make(map[string]struct{}, len(pkg.Exports)),
+			}
+
+			for _, name := range pkg.Exports {
+				exports.Exports[name] = struct{}{}
+			}
+
+			idx.byName[pkg.Name] = append(idx.byName[pkg.Name], exports)
+		}
+	}
+
+	return idx
+}
+
+// Packages returns packages of the index with the name
+func (i *Index) Packages(name string) []*PackageExports {
+	return i.byName[name]
+}
+
+// Save stores the roots which were scanned again to the dir
+func (i *Index) Save(dir string) error {
+	for _, root := range i.roots {
+		if !root.isChanged {
+			continue
+		}
+
+		if err := writeIndexedRoot(indexRootPath(dir, root.Dir), root); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scanIndexRoot parses packages of the root. Packages of the stored root are reused if their directories are not
+// modified.
+func scanIndexRoot(root *IndexRoot, stored *indexedRoot) *indexedRoot {
+	storedPackages := map[string]*indexedPackage{}
+	if stored != nil {
+		for _, pkg := range stored.Packages {
+			storedPackages[pkg.Dir] = pkg
+		}
+	}
+
+	result := &indexedRoot{
+		Version:    indexFormatVersion,
+		ImportPath: root.ImportPath,
+		Dir:        root.Dir,
+		Key:        root.Key,
+		isChanged:  true,
+	}
+
+	_ = filepath.Walk(root.Dir, func(dir string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return nil
+		}
+
+		importPath := root.ImportPath
+		if dir != root.Dir {
+			if isIgnoredIndexDir(dir, info.Name()) {
+				return filepath.SkipDir
+			}
+
+			rel, err := filepath.Rel(root.Dir, dir)
+			if err != nil {
+				return nil
+			}
+
+			importPath = path.Join(root.ImportPath, filepath.ToSlash(rel))
+			if root.SkipInternal && isInternalImportPath(importPath) {
+				return filepath.SkipDir
+			}
+		}
+
+		modTime, ok := goFilesModTime(dir, info)
+		if !ok {
+			return nil
+		}
+
+		if pkg, ok := storedPackages[dir]; ok && pkg.ModTime == modTime && pkg.ImportPath == importPath {
+			result.Packages = append(result.Packages, pkg)
+			return nil
+		}
+
+		pkg := &indexedPackage{ImportPath: importPath, Dir: dir, ModTime: modTime}
+		if exports, err := LoadPackageExports(importPath, dir); err == nil && exports != nil {
+			pkg.Name = exports.Name
+			for name := range exports.Exports {
+				pkg.Exports = append(pkg.Exports, name)
+			}
+			sort.Strings(pkg.Exports)
+		}
+
+		result.Packages = append(result.Packages, pkg)
+
+		return nil
+	})
+
+	return result
+}
+
+// isIgnoredIndexDir reports whether the directory can't contain importable packages: vendor, testdata, hidden
+// directories and nested modules(the root of GOROOT/src is a module too, its "cmd" directory is a nested module)
+func isIgnoredIndexDir(dir, name string) bool {
+	if name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+		return true
+	}
+
+	_, err := os.Stat(filepath.Join(dir, "go.mod"))
+
+	return err == nil
+}
+
+func isInternalImportPath(importPath string) bool {
+	for _, elem := range strings.Split(importPath, "/") {
+		if elem == "internal" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// goFilesModTime returns the latest modification time of the directory and its go files. false is returned if the
+// directory has no go files.
+func goFilesModTime(dir string, info os.FileInfo) (int64, bool) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return 0, false
+	}
+
+	var hasGoFiles bool
+	modTime := info.ModTime().UnixNano()
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") {
+			continue
+		}
+
+		hasGoFiles = true
+		if t := entry.ModTime().UnixNano(); t > modTime {
+			modTime = t
+		}
+	}
+
+	return modTime, hasGoFiles
+}
+
+// indexRootPath returns the path of the file to store the root in
+func indexRootPath(dir, rootDir string) string {
+	h := sha256.Sum256([]byte(rootDir))
+
+	return filepath.Join(dir, hex.EncodeToString(h[:])+".json")
+}
+
+func readIndexedRoot(filePath string) *indexedRoot {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil
+	}
+
+	var root indexedRoot
+	if err := json.Unmarshal(data, &root); err != nil || root.Version != indexFormatVersion {
+		return nil
+	}
+
+	return &root
+}
+
+// writeIndexedRoot writes the root atomically, so concurrent runs never read partially written data
+func writeIndexedRoot(filePath string, root *indexedRoot) error {
+	data, err := json.Marshal(root)
+	if err != nil {
+		return err
+	}
+
+	return fsutil.WriteFileAtomic(filePath, data)
+}
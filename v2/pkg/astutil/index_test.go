@@ -0,0 +1,85 @@
+package astutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadIndex(t *testing.T) {
+	moduleDir := t.TempDir()
+	indexDir := t.TempDir()
+
+	files := map[string]string{
+		"lib/lib.go":                    "package lib\n\nfunc New() {}\n",
+		"lib/internal/impl/impl.go":     "package impl\n\nfunc Impl() {}\n",
+		"cmd/tool/main.go":              "package main\n\nfunc Main() {}\n",
+		"testdata/lib/lib.go":           "package lib\n\nfunc Testdata() {}\n",
+		"nested/go.mod":                 "module example.com/nested\n",
+		"nested/lib/lib.go":             "package lib\n\nfunc Nested() {}\n",
+		"versioned/v2/versioned.go":     "package versioned\n\nconst Version = 2\n",
+		"versioned/v2/versioned_doc.go": "// Package versioned is versioned\npackage versioned\n",
+	}
+	for name, content := range files {
+		filePath := filepath.Join(moduleDir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		require.NoError(t, ioutil.WriteFile(filePath, []byte(content), 0644))
+	}
+
+	root := &IndexRoot{ImportPath: "example.com/module", Dir: moduleDir, SkipInternal: true}
+
+	idx := LoadIndex(indexDir, root)
+	require.NoError(t, idx.Save(indexDir))
+
+	assert.Equal(t, []*PackageExports{{
+		ImportPath: "example.com/module/lib",
+		Name:       "lib",
+		Exports:    map[string]struct{}{"New": {}},
+	}}, idx.Packages("lib"))
+	assert.Empty(t, idx.Packages("impl"))
+	assert.Empty(t, idx.Packages("main"))
+	assert.Equal(t, "example.com/module/versioned/v2", idx.Packages("versioned")[0].ImportPath)
+
+	// the content is changed, but the modification time is not, so the stored package is used
+	libPath := filepath.Join(moduleDir, "lib", "lib.go")
+	info, err := os.Stat(libPath)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(libPath, []byte("package lib\n\nfunc Open() {}\n"), 0644))
+	require.NoError(t, os.Chtimes(libPath, info.ModTime(), info.ModTime()))
+
+	idx = LoadIndex(indexDir, root)
+	assert.True(t, idx.Packages("lib")[0].HasExports("New"))
+
+	// the modified package is parsed again
+	modTime := info.ModTime().Add(time.Second)
+	require.NoError(t, os.Chtimes(libPath, modTime, modTime))
+
+	idx = LoadIndex(indexDir, root)
+	assert.True(t, idx.Packages("lib")[0].HasExports("Open"))
+	assert.False(t, idx.Packages("lib")[0].HasExports("New"))
+
+	// the stored root with the same key is used as is
+	keyedRoot := &IndexRoot{ImportPath: "example.com/module", Dir: moduleDir, Key: "v1.0.0"}
+	require.NoError(t, LoadIndex(indexDir, keyedRoot).Save(indexDir))
+
+	require.NoError(t, ioutil.WriteFile(libPath, []byte("package lib\n\nfunc Close() {}\n"), 0644))
+	modTime = modTime.Add(time.Second)
+	require.NoError(t, os.Chtimes(libPath, modTime, modTime))
+
+	idx = LoadIndex(indexDir, keyedRoot)
+	assert.True(t, idx.Packages("lib")[0].HasExports("Open"))
+	assert.Equal(t, "example.com/module/lib/internal/impl", idx.Packages("impl")[0].ImportPath)
+
+	keyedRoot.Key = "v1.0.1"
+	idx = LoadIndex(indexDir, keyedRoot)
+	assert.True(t, idx.Packages("lib")[0].HasExports("Close"))
+
+	// nothing is stored without the dir
+	idx = LoadIndex("", root)
+	assert.True(t, idx.Packages("lib")[0].HasExports("Close"))
+}
@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/psawicki5/goimports-reviser/v2/pkg/fsutil"
+)
+
+const (
+	dirName      = "goimports-reviser"
+	filesDirName = "files"
+	indexDirName = "index"
+)
+
+// Entry describes the state in which a file was known to be conforming
+type Entry struct {
+	// ContentHash is a hash of the file content
+	ContentHash string `json:"content_hash"`
+	// OptionsHash is a hash of the effective options the file was revised with
+	OptionsHash string `json:"options_hash"`
+	// ModuleHash is a hash of go.mod and go.sum of the module the file belongs to
+	ModuleHash string `json:"module_hash"`
+	// Version is a version of the tool
+	Version string `json:"version"`
+}
+
+// Stats is a summary of the cache content
+type Stats struct {
+	Dir   string
+	Files int
+	Size  int64
+}
+
+// Cache keeps track of files which are already conforming, so they can be skipped on the next run
+type Cache struct {
+	dir string
+}
+
+// DefaultDir returns a cache directory inside of the user cache dir
+func DefaultDir() (string, error) {
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(userCacheDir, dirName), nil
+}
+
+// New creates a cache stored in dir
+func New(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// Dir returns the root directory of the cache
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// IndexDir returns the directory inside of the cache to store the index of packages in
+func (c *Cache) IndexDir() string {
+	return filepath.Join(c.dir, indexDirName)
+}
+
+// IsConforming reports whether the file was marked as conforming with exactly the same entry
+func (c *Cache) IsConforming(filePath string, entry *Entry) bool {
+	entryPath, err := c.entryPath(filePath)
+	if err != nil {
+		return false
+	}
+
+	data, err := ioutil.ReadFile(entryPath)
+	if err != nil {
+		return false
+	}
+
+	var stored Entry
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return false
+	}
+
+	return stored == *entry
+}
+
+// MarkConforming stores the entry for the file
+func (c *Cache) MarkConforming(filePath string, entry *Entry) error {
+	entryPath, err := c.entryPath(filePath)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	return fsutil.WriteFileAtomic(entryPath, data)
+}
+
+// Clean removes all the cached data
+func (c *Cache) Clean() error {
+	return os.RemoveAll(c.dir)
+}
+
+// Stats returns a number of cached file entries and the total size of the cache
+func (c *Cache) Stats() (*Stats, error) {
+	stats := &Stats{Dir: c.dir}
+
+	filesDir := filepath.Join(c.dir, filesDirName)
+	err := filepath.Walk(c.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		stats.Size += info.Size()
+		if filepath.Dir(path) == filesDir {
+			stats.Files++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (c *Cache) entryPath(filePath string) (string, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(c.dir, filesDirName, Hash([]byte(absPath))), nil
+}
+
+// Hash returns a hex encoded sha256 of the data. Every part is prefixed with its length, so different splits of the
+// same bytes give different hashes.
+func Hash(data ...[]byte) string {
+	h := sha256.New()
+	for _, d := range data {
+		var size [8]byte
+		binary.BigEndian.PutUint64(size[:], uint64(len(d)))
+
+		_, _ = h.Write(size[:])
+		_, _ = h.Write(d)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
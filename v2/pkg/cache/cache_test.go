@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_IsConforming(t *testing.T) {
+	entry := &Entry{
+		ContentHash: Hash([]byte("package main\n")),
+		OptionsHash: Hash([]byte("rm-unused=true")),
+		ModuleHash:  "module",
+		Version:     "v2.0.0",
+	}
+
+	tests := []struct {
+		name  string
+		entry *Entry
+		want  bool
+	}{
+		{
+			name:  "same entry",
+			entry: entry,
+			want:  true,
+		},
+		{
+			name: "changed content",
+			entry: &Entry{
+				ContentHash: Hash([]byte("package main\n\n")),
+				OptionsHash: entry.OptionsHash,
+				ModuleHash:  entry.ModuleHash,
+				Version:     entry.Version,
+			},
+			want: false,
+		},
+		{
+			name: "changed options",
+			entry: &Entry{
+				ContentHash: entry.ContentHash,
+				OptionsHash: Hash([]byte("rm-unused=false")),
+				ModuleHash:  entry.ModuleHash,
+				Version:     entry.Version,
+			},
+			want: false,
+		},
+		{
+			name: "changed module",
+			entry: &Entry{
+				ContentHash: entry.ContentHash,
+				OptionsHash: entry.OptionsHash,
+				ModuleHash:  "module2",
+				Version:     entry.Version,
+			},
+			want: false,
+		},
+		{
+			name: "changed version",
+			entry: &Entry{
+				ContentHash: entry.ContentHash,
+				OptionsHash: entry.OptionsHash,
+				ModuleHash:  entry.ModuleHash,
+				Version:     "v2.0.1",
+			},
+			want: false,
+		},
+	}
+
+	c := New(t.TempDir())
+	require.False(t, c.IsConforming("./main.go", entry))
+	require.NoError(t, c.MarkConforming("./main.go", entry))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, c.IsConforming("./main.go", tt.entry))
+		})
+	}
+
+	assert.False(t, c.IsConforming("./other.go", entry))
+}
+
+func TestCache_StatsAndClean(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	c := New(dir)
+
+	stats, err := c.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, 0, stats.Files)
+	assert.Equal(t, dir, stats.Dir)
+
+	require.NoError(t, c.MarkConforming("./a.go", &Entry{ContentHash: "a"}))
+	require.NoError(t, c.MarkConforming("./b.go", &Entry{ContentHash: "b"}))
+	require.NoError(t, c.MarkConforming("./b.go", &Entry{ContentHash: "c"}))
+
+	stats, err = c.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Files)
+	assert.True(t, stats.Size > 0)
+
+	require.NoError(t, c.Clean())
+
+	stats, err = c.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, 0, stats.Files)
+	assert.Equal(t, int64(0), stats.Size)
+}
+
+func TestHash(t *testing.T) {
+	assert.Equal(t, Hash([]byte("ab"), []byte("c")), Hash([]byte("ab"), []byte("c")))
+	assert.NotEqual(t, Hash([]byte("ab"), []byte("c")), Hash([]byte("a"), []byte("bc")))
+}
@@ -0,0 +1,36 @@
+package fsutil
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "nested", "file.json")
+
+	require.NoError(t, WriteFileAtomic(filePath, []byte("first")))
+	require.NoError(t, WriteFileAtomic(filePath, []byte("second")))
+
+	data, err := ioutil.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+
+	// temporary files are renamed, so only the file itself is left
+	entries, err := ioutil.ReadDir(filepath.Dir(filePath))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestWriteFileAtomic_Error(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "file")
+	require.NoError(t, WriteFileAtomic(filePath, []byte("file")))
+
+	// the parent of the file is a file, so the directory can't be created
+	assert.Error(t, WriteFileAtomic(filepath.Join(filePath, "nested"), []byte("data")))
+}
@@ -1,14 +1,36 @@
 package module
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"go/build"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"golang.org/x/mod/modfile"
+	modmodule "golang.org/x/mod/module"
 )
 
-const goModFilename = "go.mod"
+const (
+	goModFilename = "go.mod"
+	goSumFilename = "go.sum"
+	vendorDirname = "vendor"
+)
+
+// Requirement is a module required by go.mod
+type Requirement struct {
+	Path    string
+	Version string
+
+	// Sum is the hash of the module content from go.sum. It's empty if go.sum has no such hash.
+	Sum string
+
+	// Dir is a directory with the source code of the module: the directory inside of vendor(if the module is
+	// vendored), the replacement directory or the directory inside of the module cache
+	Dir string
+}
 
 // Name reads module value from ./go.mod
 func Name(goModRootPath string) (string, error) {
@@ -55,3 +77,144 @@ func GoModRootPath(path string) (string, error) {
 
 	return "", nil
 }
+
+// Checksum returns a hash of go.mod and go.sum(if it exists) of the module. The checksum changes whenever requirements
+// of the module are changed.
+func Checksum(goModRootPath string) (string, error) {
+	h := sha256.New()
+
+	for _, filename := range []string{goModFilename, goSumFilename} {
+		data, err := ioutil.ReadFile(filepath.Join(goModRootPath, filename))
+		if err != nil {
+			if filename == goSumFilename && os.IsNotExist(err) {
+				continue
+			}
+
+			return "", err
+		}
+
+		_, _ = h.Write([]byte(filename))
+		_, _ = h.Write(data)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Requirements reads modules required by ./go.mod and finds directories with their source code. Directories are not
+// checked, they can be absent(ex.: the module is not downloaded yet).
+func Requirements(goModRootPath string) ([]*Requirement, error) {
+	goModFile := filepath.Join(goModRootPath, goModFilename)
+
+	data, err := ioutil.ReadFile(goModFile)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := modfile.Parse(goModFile, data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	replacements := make(map[string]modmodule.Version, len(f.Replace))
+	for _, replace := range f.Replace {
+		replacements[replace.Old.Path] = replace.New
+	}
+
+	vendorDir := filepath.Join(goModRootPath, vendorDirname)
+	hasVendor := false
+	if fi, err := os.Stat(vendorDir); err == nil && fi.IsDir() {
+		hasVendor = true
+	}
+
+	sums, err := readSums(goModRootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	requirements := make([]*Requirement, 0, len(f.Require))
+	for _, require := range f.Require {
+		requirement := &Requirement{
+			Path:    require.Mod.Path,
+			Version: require.Mod.Version,
+			Sum:     sums[require.Mod],
+		}
+
+		mod := require.Mod
+		if replacement, ok := replacements[mod.Path]; ok {
+			mod = replacement
+		}
+
+		switch {
+		case hasVendor:
+			requirement.Dir = filepath.Join(vendorDir, filepath.FromSlash(requirement.Path))
+		case mod.Version == "":
+			// the module is replaced with a local directory
+			requirement.Dir = mod.Path
+			if !filepath.IsAbs(requirement.Dir) {
+				requirement.Dir = filepath.Join(goModRootPath, requirement.Dir)
+			}
+		default:
+			requirement.Dir, err = moduleCacheDir(mod)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		requirements = append(requirements, requirement)
+	}
+
+	return requirements, nil
+}
+
+// readSums reads hashes of modules content from ./go.sum. Hashes of go.mod files are skipped.
+func readSums(goModRootPath string) (map[modmodule.Version]string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(goModRootPath, goSumFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	sums := map[modmodule.Version]string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/"+goModFilename) {
+			continue
+		}
+
+		sums[modmodule.Version{Path: fields[0], Version: fields[1]}] = fields[2]
+	}
+
+	return sums, nil
+}
+
+// moduleCacheDir returns the directory of the module inside of the module cache
+func moduleCacheDir(mod modmodule.Version) (string, error) {
+	escapedPath, err := modmodule.EscapePath(mod.Path)
+	if err != nil {
+		return "", err
+	}
+
+	escapedVersion, err := modmodule.EscapeVersion(mod.Version)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(ModCacheDir(), filepath.FromSlash(escapedPath)+"@"+escapedVersion), nil
+}
+
+// ModCacheDir returns the root directory of the module cache
+func ModCacheDir() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+
+	gopath := filepath.SplitList(build.Default.GOPATH)
+	if len(gopath) == 0 {
+		return ""
+	}
+
+	return filepath.Join(gopath[0], "pkg", "mod")
+}
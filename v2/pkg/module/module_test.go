@@ -1,7 +1,10 @@
 package module
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -145,3 +148,98 @@ func TestName(t *testing.T) {
 		})
 	}
 }
+
+func TestChecksum(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := Checksum(dir); err == nil {
+		t.Fatalf("Checksum() expected error for absent go.mod")
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, goModFilename), []byte("module example.com/test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	withoutSum, err := Checksum(dir)
+	if err != nil {
+		t.Fatalf("Checksum() error = %v", err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, goSumFilename), []byte("example.com/dep v1.0.0 h1:x=\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	withSum, err := Checksum(dir)
+	if err != nil {
+		t.Fatalf("Checksum() error = %v", err)
+	}
+
+	if withoutSum == withSum {
+		t.Errorf("Checksum() should be changed after go.sum modification")
+	}
+
+	again, err := Checksum(dir)
+	if err != nil {
+		t.Fatalf("Checksum() error = %v", err)
+	}
+
+	if again != withSum {
+		t.Errorf("Checksum() = %v, want %v", again, withSum)
+	}
+}
+
+func TestRequirements(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GOMODCACHE", filepath.Join(dir, "modcache"))
+
+	goMod := `module example.com/test
+
+require (
+	example.com/Dep v1.0.0
+	example.com/local v1.2.0
+)
+
+replace example.com/local => ./local
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, goModFilename), []byte(goMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	goSum := `example.com/Dep v1.0.0 h1:content=
+example.com/Dep v1.0.0/go.mod h1:gomod=
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, goSumFilename), []byte(goSum), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	requirements, err := Requirements(dir)
+	if err != nil {
+		t.Fatalf("Requirements() error = %v", err)
+	}
+
+	want := []*Requirement{
+		{
+			Path:    "example.com/Dep",
+			Version: "v1.0.0",
+			Sum:     "h1:content=",
+			Dir:     filepath.Join(dir, "modcache", "example.com", "!dep@v1.0.0"),
+		},
+		{Path: "example.com/local", Version: "v1.2.0", Dir: filepath.Join(dir, "local")},
+	}
+	if !reflect.DeepEqual(requirements, want) {
+		t.Errorf("Requirements() = %+v, want %+v", requirements, want)
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, vendorDirname), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	requirements, err = Requirements(dir)
+	if err != nil {
+		t.Fatalf("Requirements() error = %v", err)
+	}
+
+	if got, want := requirements[0].Dir, filepath.Join(dir, vendorDirname, "example.com", "Dep"); got != want {
+		t.Errorf("Requirements() vendored dir = %v, want %v", got, want)
+	}
+}
@@ -1,7 +1,7 @@
 // Code generated by ./gen/gen.go DO NOT EDIT.
 package std
 
-// StdPackages is a set of go libs
+// StdPackages is a set of go libs. It is shared by concurrent callers and must be treated as read-only.
 var StdPackages = map[string]struct{}{
 	"archive/tar":                          {},
 	"archive/zip":                          {},
@@ -0,0 +1,303 @@
+package reviser
+
+import (
+	"go/ast"
+	"go/token"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/psawicki5/goimports-reviser/v2/pkg/astutil"
+)
+
+// aliasPlaceholderRegexp matches placeholders of alias templates: `${group}` or `${1}`
+var aliasPlaceholderRegexp = regexp.MustCompile(`\$\{([A-Za-z0-9_]+)\}`)
+
+// AliasPolicy is a map of canonical aliases by import paths(ex.: `metav1` for `k8s.io/apimachinery/pkg/apis/meta/v1`).
+//
+// Import paths can be patterns with wildcards in path elements(the syntax of path.Match is used, ex.:
+// `k8s.io/api/*/v1`). Elements matched by wildcards can be used in the alias: `${1}` is the element matched by the
+// first wildcard, `${2}` - by the second one and so on, `${group}` is the same as `${1}`(ex.: `${group}v1` is `corev1`
+// for `k8s.io/api/core/v1`). Exact import paths take precedence over patterns, patterns with less wildcards take
+// precedence over others.
+type AliasPolicy map[string]string
+
+// aliasRule is a compiled entry of AliasPolicy
+type aliasRule struct {
+	pattern   string
+	elements  []string
+	wildcards int
+	alias     string
+}
+
+// compileAliasPolicy validates the policy and returns its rules in the order of precedence
+func compileAliasPolicy(policy AliasPolicy) ([]*aliasRule, error) {
+	rules := make([]*aliasRule, 0, len(policy))
+	for pattern, alias := range policy {
+		rule := &aliasRule{pattern: pattern, elements: strings.Split(pattern, "/"), alias: alias}
+
+		for _, element := range rule.elements {
+			if _, err := path.Match(element, ""); err != nil {
+				return nil, errors.Errorf("invalid import path pattern %q of alias policy", pattern)
+			}
+
+			if isWildcardPattern(element) {
+				rule.wildcards++
+			}
+		}
+
+		if alias == "" {
+			return nil, errors.Errorf("empty alias for %q in alias policy", pattern)
+		}
+
+		for _, match := range aliasPlaceholderRegexp.FindAllStringSubmatch(alias, -1) {
+			if index, ok := placeholderIndex(match[1]); !ok || index > rule.wildcards {
+				return nil, errors.Errorf("unknown placeholder %s in alias %q for %q", match[0], alias, pattern)
+			}
+		}
+
+		rules = append(rules, rule)
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].wildcards != rules[j].wildcards {
+			return rules[i].wildcards < rules[j].wildcards
+		}
+
+		return rules[i].pattern < rules[j].pattern
+	})
+
+	return rules, nil
+}
+
+// canonicalAlias returns the alias which is required for the import path by the rules. false is returned if the
+// import path doesn't match any rule.
+func canonicalAlias(rules []*aliasRule, importPath string) (string, bool) {
+	elements := strings.Split(importPath, "/")
+
+	for _, rule := range rules {
+		if len(rule.elements) != len(elements) {
+			continue
+		}
+
+		captures, ok := rule.match(elements)
+		if !ok {
+			continue
+		}
+
+		alias := aliasPlaceholderRegexp.ReplaceAllStringFunc(rule.alias, func(placeholder string) string {
+			index, _ := placeholderIndex(aliasPlaceholderRegexp.FindStringSubmatch(placeholder)[1])
+			return captures[index-1]
+		})
+
+		return alias, true
+	}
+
+	return "", false
+}
+
+func (r *aliasRule) match(elements []string) ([]string, bool) {
+	var captures []string
+	for i, element := range r.elements {
+		if ok, _ := path.Match(element, elements[i]); !ok {
+			return nil, false
+		}
+
+		if isWildcardPattern(element) {
+			captures = append(captures, elements[i])
+		}
+	}
+
+	return captures, true
+}
+
+func isWildcardPattern(element string) bool {
+	return strings.ContainsAny(element, `*?[\`)
+}
+
+// placeholderIndex returns the number of the wildcard which is referred by the placeholder(starting from 1)
+func placeholderIndex(name string) (int, bool) {
+	if name == "group" {
+		return 1, true
+	}
+
+	index, err := strconv.Atoi(name)
+	if err != nil || index < 1 {
+		return 0, false
+	}
+
+	return index, true
+}
+
+// hasAliasPolicyImports reports whether any import of the file is covered by the rules
+func hasAliasPolicyImports(rules []*aliasRule, f *ast.File) bool {
+	for _, importSpec := range f.Imports {
+		if _, ok := canonicalAlias(rules, strings.Trim(importSpec.Path.Value, `"`)); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// applyAliasPolicy sets canonical aliases for imports covered by the rules and replaces qualifiers of the imports in
+// the file with the aliases. The alias is not set(and it's reported with a note), if it's declared in the file or in
+// the package, or if it's used by another import.
+func applyAliasPolicy(
+	fset *token.FileSet,
+	f *ast.File,
+	filePath string,
+	rules []*aliasRule,
+	packageImports astutil.PackageImports,
+	importsWithMetadata map[string]*commentsMetadata,
+) []*Note {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	declaredNames := packageDeclaredNames(filePath, f.Name.Name)
+
+	// the alias, which is used by another import, can be released once the alias of that import is changed, so
+	// aliases are set until nothing is changed. Notes of all the passes are kept, except of notes of imports which got
+	// their aliases by later passes.
+	notesByPaths := map[string]*Note{}
+	for {
+		notes, aliased := setCanonicalAliases(
+			fset, f, filePath, rules, declaredNames, packageImports, importsWithMetadata,
+		)
+		for importPath, note := range notes {
+			notesByPaths[importPath] = note
+		}
+
+		for _, importPath := range aliased {
+			delete(notesByPaths, importPath)
+		}
+
+		if len(aliased) == 0 {
+			break
+		}
+	}
+
+	notes := make([]*Note, 0, len(notesByPaths))
+	for _, note := range notesByPaths {
+		notes = append(notes, note)
+	}
+
+	sort.Slice(notes, func(i, j int) bool {
+		if notes[i].Pos.Line != notes[j].Pos.Line {
+			return notes[i].Pos.Line < notes[j].Pos.Line
+		}
+
+		return notes[i].Pos.Column < notes[j].Pos.Column
+	})
+
+	return notes
+}
+
+// setCanonicalAliases sets canonical aliases for imports in the order of the code. The first returned value maps import
+// paths to notes about canonical aliases which can't be set, the second one lists import paths which got their
+// canonical aliases.
+func setCanonicalAliases(
+	fset *token.FileSet,
+	f *ast.File,
+	filePath string,
+	rules []*aliasRule,
+	declaredNames map[string]struct{},
+	packageImports astutil.PackageImports,
+	importsWithMetadata map[string]*commentsMetadata,
+) (map[string]*Note, []string) {
+	imports := make([]string, 0, len(importsWithMetadata))
+	for imprt := range importsWithMetadata {
+		imports = append(imports, imprt)
+	}
+
+	sort.Slice(imports, func(i, j int) bool {
+		return importsWithMetadata[imports[i]].Pos < importsWithMetadata[imports[j]].Pos
+	})
+
+	var (
+		notes   = map[string]*Note{}
+		aliased []string
+	)
+	for _, imprt := range imports {
+		alias := importAlias(imprt)
+		metadata := importsWithMetadata[imprt]
+		if alias == "_" || alias == "." || metadata.Keep {
+			continue
+		}
+
+		importPath := skipPackageAlias(imprt)
+		canonical, ok := canonicalAlias(rules, importPath)
+		if !ok {
+			continue
+		}
+
+		if !token.IsIdentifier(canonical) {
+			notes[importPath] = newNote(
+				fset, filePath, metadata.Pos,
+				"can't set alias %s for %q: it's not a valid identifier", canonical, importPath,
+			)
+			continue
+		}
+
+		name := importName(imprt, packageImports)
+		if name == canonical {
+			continue
+		}
+
+		if reason, ok := aliasConflict(f, canonical, importPath, declaredNames, packageImports, importsWithMetadata); ok {
+			notes[importPath] = newNote(
+				fset, filePath, metadata.Pos,
+				"can't set alias %s for %q: %s", canonical, importPath, reason,
+			)
+			continue
+		}
+
+		astutil.RenameQualifier(f, name, canonical)
+
+		delete(importsWithMetadata, imprt)
+		importsWithMetadata[aliasedImport(canonical, importPath, packageImports)] = metadata
+		aliased = append(aliased, importPath)
+	}
+
+	return notes, aliased
+}
+
+// aliasedImport returns the import of the path with the alias. The alias is omitted if it's the name of the package,
+// which can be assumed by its path.
+func aliasedImport(alias, importPath string, packageImports astutil.PackageImports) string {
+	if packageImports[importPath] == alias && astutil.ImportPathToAssumedName(importPath) == alias {
+		return strconv.Quote(importPath)
+	}
+
+	return alias + " " + strconv.Quote(importPath)
+}
+
+// aliasConflict returns the reason why the name can't be used as an alias of the import path
+func aliasConflict(
+	f *ast.File,
+	name, importPath string,
+	declaredNames map[string]struct{},
+	packageImports astutil.PackageImports,
+	importsWithMetadata map[string]*commentsMetadata,
+) (string, bool) {
+	for imprt := range importsWithMetadata {
+		if skipPackageAlias(imprt) != importPath && importName(imprt, packageImports) == name {
+			return "it's used by " + strconv.Quote(skipPackageAlias(imprt)) + " import", true
+		}
+	}
+
+	if _, ok := declaredNames[name]; ok {
+		return "it's declared in the package", true
+	}
+
+	if astutil.IsNameDeclared(f, name) {
+		return "it's declared in the file", true
+	}
+
+	return "", false
+}
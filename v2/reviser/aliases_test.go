@@ -0,0 +1,78 @@
+package reviser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalAlias(t *testing.T) {
+	rules, err := compileAliasPolicy(AliasPolicy{
+		"k8s.io/apimachinery/pkg/apis/meta/v1": "metav1",
+		"k8s.io/apimachinery/pkg/api/errors":   "apierrors",
+		"k8s.io/api/*/v1":                      "${group}v1",
+		"k8s.io/api/apps/v1":                   "appsv1",
+		"k8s.io/api/*/*":                       "${1}${2}",
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		importPath string
+		want       string
+		wantOk     bool
+	}{
+		{importPath: "k8s.io/apimachinery/pkg/apis/meta/v1", want: "metav1", wantOk: true},
+		{importPath: "k8s.io/apimachinery/pkg/api/errors", want: "apierrors", wantOk: true},
+		{importPath: "k8s.io/api/core/v1", want: "corev1", wantOk: true},
+		{importPath: "k8s.io/api/apps/v1", want: "appsv1", wantOk: true},
+		{importPath: "k8s.io/api/batch/v1beta1", want: "batchv1beta1", wantOk: true},
+		{importPath: "k8s.io/api/core", wantOk: false},
+		{importPath: "k8s.io/api/core/v1/extra", wantOk: false},
+		{importPath: "github.com/pkg/errors", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.importPath, func(t *testing.T) {
+			got, ok := canonicalAlias(rules, tt.importPath)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompileAliasPolicy_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  AliasPolicy
+		wantErr string
+	}{
+		{
+			name:    "invalid pattern",
+			policy:  AliasPolicy{"k8s.io/api/[/v1": "v1"},
+			wantErr: `invalid import path pattern "k8s.io/api/[/v1" of alias policy`,
+		},
+		{
+			name:    "empty alias",
+			policy:  AliasPolicy{"k8s.io/api/core/v1": ""},
+			wantErr: `empty alias for "k8s.io/api/core/v1" in alias policy`,
+		},
+		{
+			name:    "unknown placeholder",
+			policy:  AliasPolicy{"k8s.io/api/*/v1": "${version}"},
+			wantErr: `unknown placeholder ${version} in alias "${version}" for "k8s.io/api/*/v1"`,
+		},
+		{
+			name:    "placeholder without wildcard",
+			policy:  AliasPolicy{"k8s.io/api/*/v1": "${group}${2}"},
+			wantErr: `unknown placeholder ${2} in alias "${group}${2}" for "k8s.io/api/*/v1"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileAliasPolicy(tt.policy)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
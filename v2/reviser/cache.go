@@ -0,0 +1,271 @@
+package reviser
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/psawicki5/goimports-reviser/v2/pkg/astutil"
+	"github.com/psawicki5/goimports-reviser/v2/pkg/module"
+)
+
+type moduleInfo struct {
+	rootPath string
+	name     string
+
+	// stamp identifies the state of go.mod and go.sum of the module(see moduleStamp). Data of the module is loaded
+	// again, once the stamp is changed.
+	stamp string
+
+	// requiredPaths are paths of modules required by go.mod, they are loaded on demand
+	requiredPaths    []string
+	hasRequiredPaths bool
+}
+
+// moduleCache keeps metadata of modules by directories of revised files. Metadata of the module is reloaded once
+// go.mod or go.sum of the module is changed.
+type moduleCache struct {
+	mu         sync.Mutex
+	rootsByDir map[string]string
+	byRoots    map[string]*moduleInfo
+}
+
+func newModuleCache() *moduleCache {
+	return &moduleCache{
+		rootsByDir: map[string]string{},
+		byRoots:    map[string]*moduleInfo{},
+	}
+}
+
+func (c *moduleCache) get(dir string) (*moduleInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rootPath, ok := c.rootsByDir[dir]
+	if !ok {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		rootPath, err = module.GoModRootPath(absDir)
+		if err != nil {
+			return nil, err
+		}
+
+		if rootPath == "" {
+			return nil, &module.UndefinedModuleError{}
+		}
+
+		c.rootsByDir[dir] = rootPath
+	}
+
+	stamp, err := moduleStamp(rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if mod, ok := c.byRoots[rootPath]; ok && mod.stamp == stamp {
+		return mod, nil
+	}
+
+	name, err := module.Name(rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	mod := &moduleInfo{rootPath: rootPath, name: name, stamp: stamp}
+	c.byRoots[rootPath] = mod
+
+	return mod, nil
+}
+
+// requiredPaths returns paths of modules required by go.mod of the module
+func (c *moduleCache) requiredPaths(mod *moduleInfo) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if mod.hasRequiredPaths {
+		return mod.requiredPaths, nil
+	}
+
+	requirements, err := module.Requirements(mod.rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(requirements))
+	for _, requirement := range requirements {
+		paths = append(paths, requirement.Path)
+	}
+
+	mod.requiredPaths, mod.hasRequiredPaths = paths, true
+
+	return paths, nil
+}
+
+func (c *moduleCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rootsByDir = map[string]string{}
+	c.byRoots = map[string]*moduleInfo{}
+}
+
+// moduleStamp returns modification times and sizes of go.mod and go.sum(if it exists) of the module. Unlike
+// module.Checksum, it doesn't read the files, so it's cheap enough to be checked for every revised file.
+func moduleStamp(rootPath string) (string, error) {
+	var stamp strings.Builder
+	for _, filename := range []string{"go.mod", "go.sum"} {
+		fi, err := os.Stat(filepath.Join(rootPath, filename))
+		if err != nil {
+			if filename == "go.sum" && os.IsNotExist(err) {
+				continue
+			}
+
+			return "", err
+		}
+
+		fmt.Fprintf(&stamp, "%s:%d:%d;", filename, fi.ModTime().UnixNano(), fi.Size())
+	}
+
+	return stamp.String(), nil
+}
+
+type packageKey struct {
+	dir      string
+	buildTag string
+}
+
+// packageImports are names of packages imported by the loaded package
+type packageImports struct {
+	// stamp is the stamp of the module of the package at the time of loading(see moduleStamp)
+	stamp   string
+	imports astutil.PackageImports
+
+	// unresolved are imports which can't be loaded, they are not loaded again until the stamp is changed
+	unresolved map[string]struct{}
+}
+
+// missingImports returns imports of the file which are neither loaded nor known to be unresolved
+func (p *packageImports) missingImports(f *ast.File) []string {
+	var missing []string
+	for _, spec := range f.Imports {
+		// "C" is a pseudo package of cgo, which can't be loaded
+		if isCgoImport(spec) {
+			continue
+		}
+
+		importPath := strings.Trim(spec.Path.Value, `"`)
+		if _, ok := p.imports[importPath]; ok {
+			continue
+		}
+
+		if _, ok := p.unresolved[importPath]; !ok {
+			missing = append(missing, importPath)
+		}
+	}
+
+	return missing
+}
+
+// packageCache keeps names of packages imported by loaded packages. Stored maps are never modified, so they can be
+// shared between goroutines.
+type packageCache struct {
+	mu      sync.RWMutex
+	imports map[packageKey]*packageImports
+}
+
+func newPackageCache() *packageCache {
+	return &packageCache{
+		imports: map[packageKey]*packageImports{},
+	}
+}
+
+// load returns names of packages imported by the package in the dir. The package is reloaded only if the file has
+// imports which are unknown yet or if the stamp of the module of the package is changed(ex.: requirements are
+// upgraded). Imports which are not used by the package on the disk(the file can differ from its version on the disk)
+// are loaded separately, imports which can't be loaded are remembered as unresolved.
+func (c *packageCache) load(
+	ctx context.Context,
+	dir, stamp string,
+	f *ast.File,
+) (astutil.PackageImports, error) {
+	key := packageKey{dir: dir, buildTag: astutil.ParseBuildTag(f)}
+
+	c.mu.RLock()
+	cached, ok := c.imports[key]
+	c.mu.RUnlock()
+
+	if ok && cached.stamp == stamp && len(cached.missingImports(f)) == 0 {
+		return cached.imports, nil
+	}
+
+	imports, err := astutil.LoadPackageDependenciesContext(ctx, key.dir, key.buildTag)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := &packageImports{stamp: stamp, imports: imports, unresolved: map[string]struct{}{}}
+	if missing := loaded.missingImports(f); len(missing) > 0 {
+		names, err := astutil.LoadPackageNamesContext(ctx, key.dir, key.buildTag, missing...)
+		if err != nil {
+			return nil, err
+		}
+
+		for pkg, name := range names {
+			imports[pkg] = name
+		}
+
+		for _, pkg := range missing {
+			if _, ok := names[pkg]; !ok {
+				loaded.unresolved[pkg] = struct{}{}
+			}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	merged := &packageImports{
+		stamp:      stamp,
+		imports:    make(astutil.PackageImports, len(imports)),
+		unresolved: map[string]struct{}{},
+	}
+	// names of the previous state of the module can be stale
+	if cached, ok := c.imports[key]; ok && cached.stamp == stamp {
+		for pkg, name := range cached.imports {
+			merged.imports[pkg] = name
+		}
+
+		for pkg := range cached.unresolved {
+			merged.unresolved[pkg] = struct{}{}
+		}
+	}
+
+	for pkg, name := range imports {
+		merged.imports[pkg] = name
+		delete(merged.unresolved, pkg)
+	}
+
+	for pkg := range loaded.unresolved {
+		if _, ok := merged.imports[pkg]; !ok {
+			merged.unresolved[pkg] = struct{}{}
+		}
+	}
+
+	c.imports[key] = merged
+
+	return merged.imports, nil
+}
+
+func (c *packageCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.imports = map[packageKey]*packageImports{}
+}
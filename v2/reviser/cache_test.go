@@ -0,0 +1,92 @@
+package reviser
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleCache_Get(t *testing.T) {
+	dir := t.TempDir()
+	goModPath := filepath.Join(dir, "go.mod")
+
+	writeGoMod := func(content string, modTime time.Time) {
+		require.NoError(t, ioutil.WriteFile(goModPath, []byte(content), 0644))
+		require.NoError(t, os.Chtimes(goModPath, modTime, modTime))
+	}
+
+	modTime := time.Now().Add(-time.Hour)
+	writeGoMod("module example.com/first\n\nrequire github.com/pkg/errors v0.9.1\n", modTime)
+
+	c := newModuleCache()
+
+	mod, err := c.get(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com/first", mod.name)
+
+	paths, err := c.requiredPaths(mod)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"github.com/pkg/errors"}, paths)
+
+	cached, err := c.get(dir)
+	require.NoError(t, err)
+	assert.Same(t, mod, cached)
+
+	// the changed go.mod is loaded again together with its requirements
+	writeGoMod("module example.com/second\n\nrequire golang.org/x/mod v0.2.0\n", modTime.Add(time.Minute))
+
+	mod, err = c.get(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com/second", mod.name)
+
+	paths, err = c.requiredPaths(mod)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"golang.org/x/mod"}, paths)
+
+	// the cleared cache is loaded from scratch
+	c.clear()
+
+	reloaded, err := c.get(dir)
+	require.NoError(t, err)
+	assert.NotSame(t, mod, reloaded)
+	assert.Equal(t, "example.com/second", reloaded.name)
+}
+
+func TestPackageCache_Load_Unresolved(t *testing.T) {
+	writeExample(t, "package testdata\n")
+
+	f, err := parser.ParseFile(token.NewFileSet(), "", `package testdata
+
+import (
+	"fmt"
+	"not/existing"
+)
+`, parser.ImportsOnly)
+	require.NoError(t, err)
+
+	c := newPackageCache()
+
+	got, err := c.load(context.Background(), "testdata", "stamp", f)
+	require.NoError(t, err)
+	assert.Equal(t, "fmt", got["fmt"])
+
+	// the import which can't be loaded is cached too, so the package is not loaded again
+	cached := c.imports[packageKey{dir: "testdata"}]
+	require.NotNil(t, cached)
+	assert.Equal(t, map[string]struct{}{"not/existing": {}}, cached.unresolved)
+	assert.Empty(t, cached.missingImports(f))
+
+	// unresolved imports are loaded again once the module is changed
+	got, err = c.load(context.Background(), "testdata", "other stamp", f)
+	require.NoError(t, err)
+	assert.Equal(t, "fmt", got["fmt"])
+	assert.Equal(t, "other stamp", c.imports[packageKey{dir: "testdata"}].stamp)
+}
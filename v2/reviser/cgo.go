@@ -0,0 +1,172 @@
+package reviser
+
+import (
+	"bytes"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+const cgoImportPath = "C"
+
+// isCgoImport reports whether the import is the cgo pseudo-import `import "C"`
+func isCgoImport(importSpec *ast.ImportSpec) bool {
+	return strings.Trim(importSpec.Path.Value, "\"`") == cgoImportPath
+}
+
+// isCgoImportDecl reports whether the declaration is a separate `import "C"` declaration. Such declarations, together
+// with their docs(the cgo preamble), are kept as is.
+func isCgoImportDecl(dd *ast.GenDecl) bool {
+	if dd.Tok != token.IMPORT || len(dd.Specs) != 1 {
+		return false
+	}
+
+	return isCgoImport(dd.Specs[0].(*ast.ImportSpec))
+}
+
+// shouldNormalizeCgoImports reports whether `import "C"` has to be moved before other imports can be revised: it's
+// grouped together with other imports or its declaration is placed between other import declarations, which are
+// merged into one.
+func shouldNormalizeCgoImports(f *ast.File) bool {
+	var hasImportsBefore, hasCgoDeclAfterImports bool
+
+	for _, decl := range f.Decls {
+		dd, ok := decl.(*ast.GenDecl)
+		if !ok || dd.Tok != token.IMPORT {
+			continue
+		}
+
+		if isCgoImportDecl(dd) {
+			hasCgoDeclAfterImports = hasCgoDeclAfterImports || hasImportsBefore
+			continue
+		}
+
+		if hasCgoDeclAfterImports {
+			return true
+		}
+
+		for _, spec := range dd.Specs {
+			if isCgoImport(spec.(*ast.ImportSpec)) {
+				return true
+			}
+		}
+
+		hasImportsBefore = true
+	}
+
+	return false
+}
+
+// normalizeCgoImports moves every `import "C"` with its preamble to a separate declaration placed before the first
+// import declaration. The preamble and the import are copied from src byte-for-byte.
+func normalizeCgoImports(fset *token.FileSet, f *ast.File, src []byte) []byte {
+	var (
+		insertAt = -1
+		pieces   [][]byte
+		cuts     [][2]int
+	)
+
+	offset := func(pos token.Pos) int {
+		return fset.File(pos).Offset(pos)
+	}
+
+	for _, decl := range f.Decls {
+		dd, ok := decl.(*ast.GenDecl)
+		if !ok || dd.Tok != token.IMPORT {
+			continue
+		}
+
+		if insertAt < 0 {
+			insertAt = offset(nodeStart(dd, dd.Doc))
+		}
+
+		if isCgoImportDecl(dd) {
+			start, end := offset(nodeStart(dd, dd.Doc)), offset(nodeEnd(dd, dd.Specs[0].(*ast.ImportSpec).Comment))
+			pieces = append(pieces, src[start:end])
+			cuts = append(cuts, lineSpan(src, start, end))
+			continue
+		}
+
+		for _, spec := range dd.Specs {
+			importSpec := spec.(*ast.ImportSpec)
+			if !isCgoImport(importSpec) {
+				continue
+			}
+
+			var piece []byte
+			if importSpec.Doc != nil {
+				piece = append(piece, src[offset(importSpec.Doc.Pos()):offset(importSpec.Doc.End())]...)
+				piece = append(piece, '\n')
+			}
+
+			start, end := offset(nodeStart(importSpec, importSpec.Doc)), offset(nodeEnd(importSpec, importSpec.Comment))
+			piece = append(piece, "import "...)
+			piece = append(piece, src[offset(importSpec.Pos()):end]...)
+			pieces = append(pieces, piece)
+			cuts = append(cuts, lineSpan(src, start, end))
+		}
+	}
+
+	if len(pieces) == 0 {
+		return src
+	}
+
+	var buf bytes.Buffer
+	buf.Write(src[:insertAt])
+	for _, piece := range pieces {
+		buf.Write(piece)
+		buf.WriteString("\n\n")
+	}
+
+	cursor := insertAt
+	for _, cut := range cuts {
+		if cut[0] > cursor {
+			buf.Write(src[cursor:cut[0]])
+		}
+		cursor = cut[1]
+	}
+	buf.Write(src[cursor:])
+
+	return buf.Bytes()
+}
+
+// nodeStart returns the position of the node including its doc
+func nodeStart(node ast.Node, doc *ast.CommentGroup) token.Pos {
+	if doc != nil {
+		return doc.Pos()
+	}
+
+	return node.Pos()
+}
+
+// nodeEnd returns the end position of the node including its trailing comment
+func nodeEnd(node ast.Node, comment *ast.CommentGroup) token.Pos {
+	if comment != nil && comment.End() > node.End() {
+		return comment.End()
+	}
+
+	return node.End()
+}
+
+// lineSpan extends [start, end) to whole lines if there is nothing but spaces around it
+func lineSpan(src []byte, start, end int) [2]int {
+	lineStart := start
+	for lineStart > 0 && (src[lineStart-1] == ' ' || src[lineStart-1] == '\t') {
+		lineStart--
+	}
+
+	lineEnd := end
+	for lineEnd < len(src) && (src[lineEnd] == ' ' || src[lineEnd] == '\t' || src[lineEnd] == '\r') {
+		lineEnd++
+	}
+
+	if (lineStart == 0 || src[lineStart-1] == '\n') && (lineEnd == len(src) || src[lineEnd] == '\n') {
+		if lineEnd < len(src) {
+			lineEnd++
+		}
+
+		return [2]int{lineStart, lineEnd}
+	}
+
+	return [2]int{start, end}
+}
@@ -0,0 +1,172 @@
+package reviser
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// FloatingCommentsPolicy defines where to place comments inside of import declarations, which don't belong to any
+// import(ex.: section headers like "// third-party" or commented out imports). Comments are never removed.
+type FloatingCommentsPolicy int
+
+const (
+	// FloatingCommentsAttach attaches a comment to the following import, so it's moved together with the import.
+	// The comment after the last import is attached to this import.
+	FloatingCommentsAttach FloatingCommentsPolicy = iota
+
+	// FloatingCommentsGroupTop places a comment at the top of the group of the import it's attached to
+	FloatingCommentsGroupTop
+
+	// FloatingCommentsBlockTop places a comment at the top of the import block
+	FloatingCommentsBlockTop
+)
+
+// floatingComments returns comment groups inside of import declarations(or between them), which are neither docs nor
+// comments of imports
+func floatingComments(f *ast.File) []*ast.CommentGroup {
+	var (
+		start, end token.Pos
+		owned      = map[*ast.CommentGroup]struct{}{}
+	)
+
+	var isFirstImportDeclDefined bool
+	for _, decl := range f.Decls {
+		dd, ok := decl.(*ast.GenDecl)
+		if !ok || dd.Tok != token.IMPORT || isCgoImportDecl(dd) {
+			continue
+		}
+
+		if !isFirstImportDeclDefined {
+			start = dd.Pos()
+		} else if !dd.Lparen.IsValid() && dd.Doc != nil {
+			// the doc of a single-line declaration becomes the doc of its import
+			owned[dd.Doc] = struct{}{}
+		}
+
+		isFirstImportDeclDefined = true
+		end = dd.End()
+
+		for _, spec := range dd.Specs {
+			importSpec := spec.(*ast.ImportSpec)
+			if importSpec.Doc != nil {
+				owned[importSpec.Doc] = struct{}{}
+			}
+
+			if importSpec.Comment != nil {
+				owned[importSpec.Comment] = struct{}{}
+			}
+		}
+	}
+
+	var result []*ast.CommentGroup
+	for _, commentGroup := range f.Comments {
+		if commentGroup.Pos() < start || commentGroup.Pos() > end {
+			continue
+		}
+
+		if _, ok := owned[commentGroup]; ok {
+			continue
+		}
+
+		result = append(result, commentGroup)
+	}
+
+	return result
+}
+
+// attachFloatingComments attaches floating comments to the nearest following imports(or to the nearest previous
+// import, if there are no imports after the comment). Comments which can't be attached(all imports are removed) are
+// returned. Comments of `//reviser:off` regions are written with the regions, so they are neither attached nor
+// returned.
+func attachFloatingComments(f *ast.File, importsWithMetadata map[string]*commentsMetadata) []*ast.CommentGroup {
+	var (
+		imports = make([]*commentsMetadata, 0, len(importsWithMetadata))
+		regions importRegions
+	)
+	for _, metadata := range importsWithMetadata {
+		if metadata.Region != nil {
+			regions = append(regions, metadata.Region)
+			continue
+		}
+
+		imports = append(imports, metadata)
+	}
+
+	sort.Slice(imports, func(i, j int) bool {
+		return imports[i].Pos < imports[j].Pos
+	})
+
+	var detached []*ast.CommentGroup
+	for _, commentGroup := range floatingComments(f) {
+		if regions.find(commentGroup.Pos()) != nil {
+			continue
+		}
+
+		if len(imports) == 0 {
+			detached = append(detached, commentGroup)
+			continue
+		}
+
+		idx := sort.Search(len(imports), func(i int) bool {
+			return imports[i].Pos > commentGroup.Pos()
+		})
+
+		if idx < len(imports) {
+			imports[idx].FloatingBefore = append(imports[idx].FloatingBefore, commentGroup)
+			continue
+		}
+
+		last := imports[len(imports)-1]
+		last.FloatingAfter = append(last.FloatingAfter, commentGroup)
+	}
+
+	return detached
+}
+
+// floatingCommentsText returns all floating comments attached to the imports in the order of the original code
+func floatingCommentsText(imports []string, importsWithMetadata map[string]*commentsMetadata) string {
+	var commentGroups []*ast.CommentGroup
+	for _, imprt := range imports {
+		metadata, ok := importsWithMetadata[imprt]
+		if !ok || metadata == nil {
+			continue
+		}
+
+		commentGroups = append(commentGroups, metadata.FloatingBefore...)
+		commentGroups = append(commentGroups, metadata.FloatingAfter...)
+	}
+
+	if len(commentGroups) == 0 {
+		return ""
+	}
+
+	sort.Slice(commentGroups, func(i, j int) bool {
+		return commentGroups[i].Pos() < commentGroups[j].Pos()
+	})
+
+	return commentGroupsText(commentGroups) + "\n"
+}
+
+// commentGroupsText returns the comments of the groups as they are written in the code, one comment per line
+func commentGroupsText(commentGroups []*ast.CommentGroup) string {
+	var lines []string
+	for _, commentGroup := range commentGroups {
+		for _, comment := range commentGroup.List {
+			lines = append(lines, comment.Text)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// trailingCommentText returns the comments placed after the import on the same line as they are written in the code
+func trailingCommentText(commentGroup *ast.CommentGroup) string {
+	comments := make([]string, 0, len(commentGroup.List))
+	for _, comment := range commentGroup.List {
+		comments = append(comments, comment.Text)
+	}
+
+	return strings.Join(comments, " ")
+}
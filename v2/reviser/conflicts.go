@@ -0,0 +1,261 @@
+package reviser
+
+import (
+	"context"
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/psawicki5/goimports-reviser/v2/pkg/astutil"
+)
+
+// resolveNameConflicts assigns aliases to imports of different paths, which are referred by the same name(ex.:
+// `errors` and `github.com/pkg/errors`). Imports of the conflict get aliases qualified by parent elements of their
+// paths(ex.: `appsv1` for `k8s.io/api/apps/v1`), except of std imports, which keep their names unless renameStd is
+// set, and imports kept by directives. If no import keeps the name this way, the first import of the conflict keeps
+// it, so qualifiers which can't be attributed still refer to an import.
+//
+// Qualifiers of the conflicting imports are attributed to the imports by identifiers which they select: the
+// identifier should be exported only by one of the packages, otherwise it's attributed to the import which keeps the
+// name and left as is.
+func (r *Reviser) resolveNameConflicts(
+	ctx context.Context,
+	fset *token.FileSet,
+	f *ast.File,
+	filePath string,
+	renameStd bool,
+	packageImports astutil.PackageImports,
+	importsWithMetadata map[string]*commentsMetadata,
+) ([]*Note, error) {
+	byNames := map[string][]string{}
+	for imprt := range importsWithMetadata {
+		if alias := importAlias(imprt); alias == "_" || alias == "." {
+			continue
+		}
+
+		name := importName(imprt, packageImports)
+		byNames[name] = append(byNames[name], imprt)
+	}
+
+	names := make([]string, 0, len(byNames))
+	for name, imports := range byNames {
+		if len(imports) > 1 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var (
+		notes         []*Note
+		declaredNames map[string]struct{}
+	)
+	for _, name := range names {
+		imports := byNames[name]
+		sort.Slice(imports, func(i, j int) bool {
+			return importsWithMetadata[imports[i]].Pos < importsWithMetadata[imports[j]].Pos
+		})
+
+		if declaredNames == nil {
+			declaredNames = packageDeclaredNames(filePath, f.Name.Name)
+		}
+
+		keepsName := func(imprt string) bool {
+			_, isStd := r.stdPackages[skipPackageAlias(imprt)]
+			return (isStd && !renameStd) || importsWithMetadata[imprt].Keep
+		}
+
+		var kept []string
+		for _, imprt := range imports {
+			if keepsName(imprt) {
+				kept = append(kept, imprt)
+			}
+		}
+
+		if len(kept) == 0 {
+			kept = append(kept, imports[0])
+		}
+
+		aliases := map[string]string{}
+		for _, imprt := range imports {
+			if imprt == kept[0] || keepsName(imprt) {
+				continue
+			}
+
+			alias, ok := conflictAlias(
+				f, skipPackageAlias(imprt), aliases, declaredNames, packageImports, importsWithMetadata,
+			)
+			if !ok {
+				kept = append(kept, imprt)
+				continue
+			}
+
+			aliases[imprt] = alias
+		}
+
+		if len(kept) > 1 {
+			notes = append(notes, newNote(
+				fset, filePath, importsWithMetadata[kept[1]].Pos,
+				"can't resolve conflict of imports named %s: %s", name, quotedImportPaths(kept),
+			))
+			continue
+		}
+
+		exports, err := importExports(ctx, f, filePath, imports)
+		if err != nil {
+			return nil, &PackageLoadError{FilePath: filePath, Err: err}
+		}
+
+		renameConflictQualifiers(f, name, imports, kept[0], exports, aliases)
+
+		for _, imprt := range imports {
+			alias, ok := aliases[imprt]
+			if !ok {
+				continue
+			}
+
+			metadata := importsWithMetadata[imprt]
+			delete(importsWithMetadata, imprt)
+			importsWithMetadata[aliasedImport(alias, skipPackageAlias(imprt), packageImports)] = metadata
+
+			notes = append(notes, newNote(
+				fset, filePath, metadata.Pos,
+				"%q is imported as %s, because %s is used by other imports", skipPackageAlias(imprt), alias, name,
+			))
+		}
+	}
+
+	sort.SliceStable(notes, func(i, j int) bool {
+		return notes[i].Pos.Offset < notes[j].Pos.Offset
+	})
+
+	return notes, nil
+}
+
+// conflictAlias returns the alias qualified by the parent element of the path(suffixed with a number if it's taken),
+// which is not used by other imports or declared in the file or in the package
+func conflictAlias(
+	f *ast.File,
+	importPath string,
+	aliases map[string]string,
+	declaredNames map[string]struct{},
+	packageImports astutil.PackageImports,
+	importsWithMetadata map[string]*commentsMetadata,
+) (string, bool) {
+	qualified := qualifiedAlias(importPath)
+	if qualified == "" {
+		return "", false
+	}
+
+	taken := make(map[string]struct{}, len(aliases))
+	for _, alias := range aliases {
+		taken[alias] = struct{}{}
+	}
+
+	for i := 1; i < 10; i++ {
+		candidate := qualified
+		if i > 1 {
+			candidate += strconv.Itoa(i)
+		}
+
+		if _, ok := taken[candidate]; ok {
+			continue
+		}
+
+		if _, ok := aliasConflict(f, candidate, importPath, declaredNames, packageImports, importsWithMetadata); !ok {
+			return candidate, true
+		}
+	}
+
+	return "", false
+}
+
+// renameConflictQualifiers replaces qualifiers of the conflicting imports with the aliases of the imports, which
+// the qualifiers are attributed to(see resolveNameConflicts)
+func renameConflictQualifiers(
+	f *ast.File,
+	name string,
+	imports []string,
+	keptImport string,
+	exports map[string]*astutil.PackageExports,
+	aliases map[string]string,
+) {
+	ast.Inspect(f, func(node ast.Node) bool {
+		sel, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		ident, ok := sel.X.(*ast.Ident)
+		if !ok || ident.Obj != nil || ident.Name != name {
+			return true
+		}
+
+		var exporters []string
+		for _, imprt := range imports {
+			if pkg := exports[imprt]; pkg != nil && pkg.HasExports(sel.Sel.Name) {
+				exporters = append(exporters, imprt)
+			}
+		}
+
+		owner := keptImport
+		if len(exporters) == 1 {
+			owner = exporters[0]
+		}
+
+		if alias, ok := aliases[owner]; ok {
+			ident.Name = alias
+		}
+
+		return true
+	})
+}
+
+// importExports returns exported identifiers of the imported packages by the imports. Only these packages are loaded
+// from the directory of the file, the imports of packages which can't be loaded are skipped.
+func importExports(
+	ctx context.Context,
+	f *ast.File,
+	filePath string,
+	imports []string,
+) (map[string]*astutil.PackageExports, error) {
+	importPaths := make([]string, 0, len(imports))
+	for _, imprt := range imports {
+		importPaths = append(importPaths, skipPackageAlias(imprt))
+	}
+
+	pkgs, err := astutil.LoadPackageTypesContext(ctx, filepath.Dir(filePath), astutil.ParseBuildTag(f), importPaths...)
+	if err != nil {
+		return nil, err
+	}
+
+	exports := make(map[string]*astutil.PackageExports, len(imports))
+	for _, imprt := range imports {
+		pkg, ok := pkgs[skipPackageAlias(imprt)]
+		if !ok {
+			continue
+		}
+
+		pkgExports := &astutil.PackageExports{ImportPath: pkg.Path(), Name: pkg.Name(), Exports: map[string]struct{}{}}
+		for _, name := range pkg.Scope().Names() {
+			if token.IsExported(name) {
+				pkgExports.Exports[name] = struct{}{}
+			}
+		}
+
+		exports[imprt] = pkgExports
+	}
+
+	return exports, nil
+}
+
+func quotedImportPaths(imports []string) string {
+	paths := make([]string, 0, len(imports))
+	for _, imprt := range imports {
+		paths = append(paths, strconv.Quote(skipPackageAlias(imprt)))
+	}
+
+	return strings.Join(paths, ", ")
+}
@@ -0,0 +1,180 @@
+package reviser
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+const (
+	embedDirective    = "//go:embed"
+	linknameDirective = "//go:linkname"
+
+	embedImportPath  = "embed"
+	unsafeImportPath = "unsafe"
+)
+
+// directiveImports are imports required by compiler directives: `//go:embed` requires "embed" and `//go:linkname`
+// requires "unsafe". The blank "embed" import has no other purpose, so it's removed once it isn't required.
+// The blank "unsafe" import is kept, because it could be added for other reasons.
+var directiveImports = []struct {
+	directive   string
+	importPath  string
+	isRemovable bool
+}{
+	{directive: embedDirective, importPath: embedImportPath, isRemovable: true},
+	{directive: linknameDirective, importPath: unsafeImportPath},
+}
+
+// fixDirectiveImports adds blank imports required by compiler directives, if the packages are not imported, and
+// removes the blank "embed" import if there are no `//go:embed` directives anymore. The changes are reported with
+// notes.
+func fixDirectiveImports(
+	fset *token.FileSet,
+	f *ast.File,
+	filePath string,
+	importsWithMetadata map[string]*commentsMetadata,
+) []*Note {
+	var notes []*Note
+
+	for _, directiveImport := range directiveImports {
+		directive := findDirective(f, directiveImport.directive)
+		blankImport := blankImportSpec(directiveImport.importPath)
+
+		if directive == nil {
+			metadata, ok := importsWithMetadata[blankImport]
+			if !ok || !directiveImport.isRemovable || metadata.Keep {
+				continue
+			}
+
+			delete(importsWithMetadata, blankImport)
+			notes = append(notes, newNote(
+				fset, filePath, metadata.Pos,
+				"removed %s import: there are no %s directives", blankImport, directiveImport.directive,
+			))
+
+			continue
+		}
+
+		if hasImportPath(importsWithMetadata, directiveImport.importPath) {
+			continue
+		}
+
+		importsWithMetadata[blankImport] = &commentsMetadata{}
+		notes = append(notes, newNote(
+			fset, filePath, directive.Pos(),
+			"added %s import required by %s directive", blankImport, directiveImport.directive,
+		))
+	}
+
+	return notes
+}
+
+// findDirective returns the first comment with the directive
+func findDirective(f *ast.File, directive string) *ast.Comment {
+	for _, commentGroup := range f.Comments {
+		for _, comment := range commentGroup.List {
+			if isDirectiveComment(comment, directive) {
+				return comment
+			}
+		}
+	}
+
+	return nil
+}
+
+// isDirectiveComment reports whether the comment is the directive, optionally followed by arguments
+func isDirectiveComment(comment *ast.Comment, directive string) bool {
+	if !strings.HasPrefix(comment.Text, directive) {
+		return false
+	}
+
+	rest := comment.Text[len(directive):]
+
+	return rest == "" || rest[0] == ' ' || rest[0] == '\t'
+}
+
+func blankImportSpec(importPath string) string {
+	return `_ "` + importPath + `"`
+}
+
+func hasImportPath(importsWithMetadata map[string]*commentsMetadata, importPath string) bool {
+	for imprt := range importsWithMetadata {
+		if skipPackageAlias(imprt) == importPath {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasImportDecl reports whether the file has import declarations(except `import "C"`)
+func hasImportDecl(f *ast.File) bool {
+	for _, decl := range f.Decls {
+		if dd, ok := decl.(*ast.GenDecl); ok && dd.Tok == token.IMPORT && !isCgoImportDecl(dd) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addImportDecl inserts an import declaration with the groups of imports after the package clause(and `import "C"`
+// declarations) of the code. It's used for files without import declarations, which can't be inserted into the AST
+// without breaking positions of comments.
+func addImportDecl(
+	src []byte,
+	commentsMetadata map[string]*commentsMetadata,
+	groups ...[]string,
+) ([]byte, error) {
+	fset := token.NewFileSet()
+
+	f, err := parser.ParseFile(fset, "", src, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	pos := f.Name.End()
+	// the trailing comment of the package clause stays on its line
+	for _, commentGroup := range f.Comments {
+		if commentGroup.Pos() > pos && fset.Position(commentGroup.Pos()).Line == fset.Position(pos).Line {
+			pos = commentGroup.End()
+		}
+	}
+
+	for _, decl := range f.Decls {
+		if dd, ok := decl.(*ast.GenDecl); ok && dd.Tok == token.IMPORT {
+			pos = nodeEnd(dd, dd.Specs[0].(*ast.ImportSpec).Comment)
+		}
+	}
+
+	var imports []string
+	for _, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+
+		var values []string
+		for _, imprt := range group {
+			values = append(values, importWithComment(imprt, commentsMetadata, true))
+		}
+
+		imports = append(imports, strings.Join(values, "\n"))
+	}
+
+	decl := "import " + imports[0]
+	if len(imports) > 1 || strings.Contains(imports[0], "\n") {
+		decl = "import (\n" + strings.Join(imports, "\n\n") + "\n)"
+	}
+
+	offset := fset.File(pos).Offset(pos)
+
+	var buf bytes.Buffer
+	buf.Write(src[:offset])
+	buf.WriteString("\n\n" + decl)
+	buf.Write(src[offset:])
+
+	return buf.Bytes(), nil
+}
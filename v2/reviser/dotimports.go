@@ -0,0 +1,204 @@
+package reviser
+
+import (
+	"context"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/psawicki5/goimports-reviser/v2/pkg/astutil"
+)
+
+// isDotImportAllowed reports whether dot imports are allowed in the file: its name matches any of the patterns(ex.:
+// `*_test.go`)
+func (r *Reviser) isDotImportAllowed(filePath string) bool {
+	for _, pattern := range r.dotImportsAllowed {
+		if ok, _ := filepath.Match(pattern, filepath.Base(filePath)); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// removeDotImports replaces dot imports with named imports and qualifies identifiers of the file, which refer to the
+// imported packages(ex.: `Expect` becomes `gomega.Expect`). The name of the package is used, unless the alias policy
+// requires another alias. Identifiers are resolved by type checking of the file against packages loaded from the
+// directory of the file, so the dot import is kept(and it's reported with a note), if its package can't be loaded.
+// Dot imports kept by directives are skipped.
+func (r *Reviser) removeDotImports(
+	ctx context.Context,
+	fset *token.FileSet,
+	f *ast.File,
+	filePath string,
+	packageImports astutil.PackageImports,
+	importsWithMetadata map[string]*commentsMetadata,
+) ([]*Note, error) {
+	var dotImports []string
+	for imprt, metadata := range importsWithMetadata {
+		if importAlias(imprt) == "." && !metadata.Keep {
+			dotImports = append(dotImports, imprt)
+		}
+	}
+
+	if len(dotImports) == 0 {
+		return nil, nil
+	}
+
+	sort.Slice(dotImports, func(i, j int) bool {
+		return importsWithMetadata[dotImports[i]].Pos < importsWithMetadata[dotImports[j]].Pos
+	})
+
+	// all imports of the file are loaded, so identifiers of the dot imports are not confused with unresolved ones
+	importPaths := make([]string, 0, len(f.Imports))
+	for _, spec := range f.Imports {
+		if !isCgoImport(spec) {
+			importPaths = append(importPaths, strings.Trim(spec.Path.Value, `"`))
+		}
+	}
+
+	imported, err := astutil.LoadPackageTypesContext(
+		ctx, filepath.Dir(filePath), astutil.ParseBuildTag(f), importPaths...,
+	)
+	if err != nil {
+		return nil, &PackageLoadError{FilePath: filePath, Err: err}
+	}
+
+	info := &types.Info{Uses: map[*ast.Ident]types.Object{}}
+	cfg := &types.Config{
+		Importer: loadedImporter(imported),
+		// identifiers declared in other files of the package are unresolved, but they don't refer to dot imports
+		Error: func(error) {},
+	}
+
+	_, _ = cfg.Check(f.Name.Name, fset, []*ast.File{f}, info)
+
+	var (
+		notes         []*Note
+		declaredNames = packageDeclaredNames(filePath, f.Name.Name)
+	)
+	for _, imprt := range dotImports {
+		importPath := skipPackageAlias(imprt)
+		metadata := importsWithMetadata[imprt]
+
+		importedPkg, ok := imported[importPath]
+		if !ok {
+			notes = append(notes, newNote(
+				fset, filePath, metadata.Pos,
+				"can't remove dot import of %q: package can't be loaded", importPath,
+			))
+			continue
+		}
+
+		packageName := importedPkg.Name()
+		if canonical, ok := canonicalAlias(r.aliasRules, importPath); ok && token.IsIdentifier(canonical) {
+			packageName = canonical
+		}
+
+		name, existingImport := dotImportName(importPath, packageName, packageImports, importsWithMetadata)
+		if existingImport == "" {
+			if _, conflict := aliasConflict(
+				f, name, importPath, declaredNames, packageImports, importsWithMetadata,
+			); conflict {
+				var ok bool
+				name, ok = conflictAlias(f, importPath, nil, declaredNames, packageImports, importsWithMetadata)
+				if !ok {
+					notes = append(notes, newNote(
+						fset, filePath, metadata.Pos,
+						"can't remove dot import of %q: %s is declared or used by other imports",
+						importPath, packageName,
+					))
+					continue
+				}
+			}
+		}
+
+		qualifyDotImportIdents(f, info, importedPkg, name)
+
+		delete(importsWithMetadata, imprt)
+		if existingImport != "" {
+			importsWithMetadata[existingImport].mergeDuplicate(metadata.Doc, metadata.Comment, metadata.Pos)
+		} else {
+			// the name of the package is known by type checking, even if names of imports are not loaded
+			packageNames := astutil.PackageImports{importPath: importedPkg.Name()}
+			importsWithMetadata[aliasedImport(name, importPath, packageNames)] = metadata
+		}
+
+		notes = append(notes, newNote(
+			fset, filePath, metadata.Pos,
+			"dot import of %q is replaced with %s", importPath, name,
+		))
+	}
+
+	return notes, nil
+}
+
+// loadedImporter imports packages which are loaded already by their import paths
+type loadedImporter map[string]*types.Package
+
+func (i loadedImporter) Import(importPath string) (*types.Package, error) {
+	pkg, ok := i[importPath]
+	if !ok {
+		return nil, errors.Errorf("package %q is not loaded", importPath)
+	}
+
+	return pkg, nil
+}
+
+// dotImportName returns the name to qualify identifiers of the dot import with. If the path is imported without dot
+// too, the name of that import is used and the import is returned.
+func dotImportName(
+	importPath, packageName string,
+	packageImports astutil.PackageImports,
+	importsWithMetadata map[string]*commentsMetadata,
+) (string, string) {
+	for imprt := range importsWithMetadata {
+		if skipPackageAlias(imprt) != importPath {
+			continue
+		}
+
+		if alias := importAlias(imprt); alias != "." && alias != "_" {
+			return importName(imprt, packageImports), imprt
+		}
+	}
+
+	return packageName, ""
+}
+
+// qualifyDotImportIdents qualifies identifiers which refer to package-level objects of the package with the name.
+// Selected identifiers of selectors(ex.: `Sel` in `x.Sel`) are skipped, because they are qualified already.
+func qualifyDotImportIdents(node ast.Node, info *types.Info, pkg *types.Package, name string) {
+	ast.Inspect(node, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.SelectorExpr:
+			qualifyDotImportIdents(n.X, info, pkg, name)
+			return false
+		case *ast.ImportSpec:
+			return false
+		}
+
+		qualifyDotImportIdent(node, info, pkg, name)
+
+		return true
+	})
+}
+
+func qualifyDotImportIdent(node ast.Node, info *types.Info, pkg *types.Package, name string) {
+	ident, ok := node.(*ast.Ident)
+	if !ok {
+		return
+	}
+
+	obj, ok := info.Uses[ident]
+	if !ok || obj.Pkg() != pkg || obj.Parent() != pkg.Scope() {
+		return
+	}
+
+	// the identifier is printed as is, so the selector expression is not needed
+	ident.Name = name + "." + ident.Name
+}
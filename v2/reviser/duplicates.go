@@ -0,0 +1,195 @@
+package reviser
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+
+	"github.com/psawicki5/goimports-reviser/v2/pkg/astutil"
+)
+
+// mergeDuplicateImports merges imports of the same path with different names into one import. Blank imports are
+// merged into any other import of the path. If imports are named differently, the import without alias(or the first
+// import) survives and qualifiers of other imports are replaced with its name. Imports are not merged, if it's not
+// safe: the name of the surviving import is declared in the file or in the package, or it's used by another import.
+// Dot imports and imports kept by directives are never merged. Merged imports, as well as imports which can't be
+// merged, are reported with notes.
+func mergeDuplicateImports(
+	fset *token.FileSet,
+	f *ast.File,
+	filePath string,
+	packageImports astutil.PackageImports,
+	importsWithMetadata map[string]*commentsMetadata,
+) []*Note {
+	var notes []*Note
+
+	byPaths := map[string][]string{}
+	for imprt, metadata := range importsWithMetadata {
+		importPath := skipPackageAlias(imprt)
+
+		for _, pos := range metadata.Duplicates {
+			notes = append(notes, newNote(fset, filePath, pos, "merged duplicate import of %q", importPath))
+		}
+
+		if name := importAlias(imprt); name != "." && !metadata.Keep {
+			byPaths[importPath] = append(byPaths[importPath], imprt)
+		}
+	}
+
+	importPaths := make([]string, 0, len(byPaths))
+	for importPath, imports := range byPaths {
+		if len(imports) > 1 {
+			importPaths = append(importPaths, importPath)
+		}
+	}
+	sort.Strings(importPaths)
+
+	var declaredNames map[string]struct{}
+	for _, importPath := range importPaths {
+		imports := byPaths[importPath]
+		sort.Slice(imports, func(i, j int) bool {
+			return importsWithMetadata[imports[i]].Pos < importsWithMetadata[imports[j]].Pos
+		})
+
+		// the import without alias is preferred, because it's the canonical name of the package
+		sort.SliceStable(imports, func(i, j int) bool {
+			return importAlias(imports[i]) == "" && importAlias(imports[j]) != ""
+		})
+
+		var named, blank []string
+		for _, imprt := range imports {
+			if importAlias(imprt) == "_" {
+				blank = append(blank, imprt)
+				continue
+			}
+
+			named = append(named, imprt)
+		}
+
+		if len(named) == 0 {
+			continue
+		}
+
+		survivor := named[0]
+		if len(named) > 1 {
+			if declaredNames == nil {
+				declaredNames = packageDeclaredNames(filePath, f.Name.Name)
+			}
+
+			var ok bool
+			survivor, ok = selectSurvivingImport(f, named, declaredNames, packageImports, importsWithMetadata)
+			if !ok {
+				survivor = named[0]
+				notes = append(notes, newNote(
+					fset, filePath, importsWithMetadata[named[1]].Pos,
+					"can't merge duplicate imports of %q: names %s are declared or used by other imports",
+					importPath, strings.Join(importNames(named, packageImports), ", "),
+				))
+
+				// blank imports are merged anyway
+				named = named[:1]
+			}
+		}
+
+		survivorName := importName(survivor, packageImports)
+		for _, imprt := range append(named, blank...) {
+			if imprt == survivor {
+				continue
+			}
+
+			metadata := importsWithMetadata[imprt]
+			importsWithMetadata[survivor].mergeDuplicate(metadata.Doc, metadata.Comment, metadata.Pos)
+			delete(importsWithMetadata, imprt)
+
+			name := importName(imprt, packageImports)
+			if name == "_" || name == survivorName || astutil.RenameQualifier(f, name, survivorName) == 0 {
+				notes = append(notes, newNote(fset, filePath, metadata.Pos, "merged duplicate import of %q", importPath))
+				continue
+			}
+
+			notes = append(notes, newNote(
+				fset, filePath, metadata.Pos,
+				"merged duplicate import of %q: %s is replaced with %s", importPath, name, survivorName,
+			))
+		}
+	}
+
+	sort.SliceStable(notes, func(i, j int) bool {
+		return notes[i].Pos.Offset < notes[j].Pos.Offset
+	})
+
+	return notes
+}
+
+// selectSurvivingImport returns the first import, which name can replace names of other imports: it's not declared
+// in the file or in the package and it isn't used by imports of other paths
+func selectSurvivingImport(
+	f *ast.File,
+	imports []string,
+	declaredNames map[string]struct{},
+	packageImports astutil.PackageImports,
+	importsWithMetadata map[string]*commentsMetadata,
+) (string, bool) {
+	importPath := skipPackageAlias(imports[0])
+
+	otherNames := map[string]struct{}{}
+	for imprt := range importsWithMetadata {
+		if skipPackageAlias(imprt) != importPath {
+			otherNames[importName(imprt, packageImports)] = struct{}{}
+		}
+	}
+
+	for _, imprt := range imports {
+		name := importName(imprt, packageImports)
+		if _, ok := otherNames[name]; ok {
+			continue
+		}
+
+		if _, ok := declaredNames[name]; ok {
+			continue
+		}
+
+		if astutil.IsNameDeclared(f, name) {
+			continue
+		}
+
+		return imprt, true
+	}
+
+	return "", false
+}
+
+// importAlias returns the alias of the import(ex.: `pkgerrors` for `pkgerrors "github.com/pkg/errors"`). It's empty
+// if the import has no alias.
+func importAlias(imprt string) string {
+	values := strings.Split(imprt, " ")
+	if len(values) > 1 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// importName returns the name which the package is referred by in the file: the alias or the name of the package
+func importName(imprt string, packageImports astutil.PackageImports) string {
+	if alias := importAlias(imprt); alias != "" {
+		return alias
+	}
+
+	importPath := skipPackageAlias(imprt)
+	if name, ok := packageImports[importPath]; ok && name != "" {
+		return name
+	}
+
+	return astutil.ImportPathToAssumedName(importPath)
+}
+
+func importNames(imports []string, packageImports astutil.PackageImports) []string {
+	names := make([]string, 0, len(imports))
+	for _, imprt := range imports {
+		names = append(names, importName(imprt, packageImports))
+	}
+
+	return names
+}
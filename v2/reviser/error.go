@@ -0,0 +1,125 @@
+package reviser
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+
+	"github.com/pkg/errors"
+)
+
+// ParseError will appear if the file can't be parsed
+type ParseError struct {
+	FilePath string
+	// Pos is the position of the first syntax error
+	Pos token.Position
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	var list scanner.ErrorList
+	if errors.As(e.Err, &list) && len(list) > 1 {
+		return errorString(e.FilePath, e.Pos, fmt.Sprintf("%s (and %d more errors)", list[0].Msg, len(list)-1))
+	}
+
+	if len(list) == 1 {
+		return errorString(e.FilePath, e.Pos, list[0].Msg)
+	}
+
+	return errorString(e.FilePath, e.Pos, e.Err.Error())
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// PackageLoadError will appear if dependencies of the package of the file can't be loaded
+type PackageLoadError struct {
+	FilePath string
+	Err      error
+}
+
+func (e *PackageLoadError) Error() string {
+	return errorString(e.FilePath, token.Position{}, fmt.Sprintf("loading package deps: %s", e.Err))
+}
+
+func (e *PackageLoadError) Unwrap() error {
+	return e.Err
+}
+
+// FormatError will appear if the revised code can't be printed or formatted. The revised code is generated, so
+// positions of its errors don't match positions of the file and they are not reported.
+type FormatError struct {
+	FilePath string
+	Err      error
+}
+
+func (e *FormatError) Error() string {
+	var list scanner.ErrorList
+	if errors.As(e.Err, &list) && len(list) > 0 {
+		return errorString(e.FilePath, token.Position{}, fmt.Sprintf("formatting: %s", list[0].Msg))
+	}
+
+	return errorString(e.FilePath, token.Position{}, fmt.Sprintf("formatting: %s", e.Err))
+}
+
+func (e *FormatError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigError will appear on invalid configuration or if the configuration can't be applied to the file(ex.: the
+// project name is not set and the file is not a part of any module)
+type ConfigError struct {
+	// FilePath is empty if the configuration is invalid itself
+	FilePath string
+	Err      error
+}
+
+func (e *ConfigError) Error() string {
+	return errorString(e.FilePath, token.Position{}, fmt.Sprintf("invalid configuration: %s", e.Err))
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+func newParseError(filePath string, err error) *ParseError {
+	return &ParseError{
+		FilePath: filePath,
+		Pos:      errorPosition(filePath, err),
+		Err:      err,
+	}
+}
+
+func newFormatError(filePath string, err error) *FormatError {
+	return &FormatError{
+		FilePath: filePath,
+		Err:      err,
+	}
+}
+
+// errorPosition returns the position of the first error in the list(which is returned by the parser)
+func errorPosition(filePath string, err error) token.Position {
+	var list scanner.ErrorList
+	if !errors.As(err, &list) || len(list) == 0 {
+		return token.Position{}
+	}
+
+	pos := list[0].Pos
+	pos.Filename = filePath
+
+	return pos
+}
+
+// errorString formats the message like the go toolchain does: "file:line:col: message"
+func errorString(filePath string, pos token.Position, msg string) string {
+	if pos.IsValid() {
+		return fmt.Sprintf("%s: %s", pos, msg)
+	}
+
+	if filePath != "" {
+		return fmt.Sprintf("%s: %s", filePath, msg)
+	}
+
+	return msg
+}
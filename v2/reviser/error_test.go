@@ -0,0 +1,135 @@
+package reviser
+
+import (
+	"go/scanner"
+	"go/token"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestParseError_Error(t *testing.T) {
+	pos := token.Position{Filename: "a.go", Line: 3, Column: 5}
+
+	tests := []struct {
+		name string
+		err  *ParseError
+		want string
+	}{
+		{
+			name: "single error",
+			err: &ParseError{
+				FilePath: "a.go",
+				Pos:      pos,
+				Err:      scanner.ErrorList{{Pos: pos, Msg: "expected ';'"}},
+			},
+			want: "a.go:3:5: expected ';'",
+		},
+		{
+			name: "multiple errors",
+			err: &ParseError{
+				FilePath: "a.go",
+				Pos:      pos,
+				Err:      scanner.ErrorList{{Pos: pos, Msg: "expected ';'"}, {Pos: pos, Msg: "expected '}'"}},
+			},
+			want: "a.go:3:5: expected ';' (and 1 more errors)",
+		},
+		{
+			name: "without position",
+			err: &ParseError{
+				FilePath: "a.go",
+				Err:      errors.New("some error"),
+			},
+			want: "a.go: some error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackageLoadError_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *PackageLoadError
+		want string
+	}{
+		{
+			name: "success",
+			err:  &PackageLoadError{FilePath: "a.go", Err: errors.New("package has an errors")},
+			want: "a.go: loading package deps: package has an errors",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatError_Error(t *testing.T) {
+	pos := token.Position{Filename: "a.go", Line: 4, Column: 2}
+
+	tests := []struct {
+		name string
+		err  *FormatError
+		want string
+	}{
+		{
+			name: "with syntax error",
+			err: &FormatError{
+				FilePath: "a.go",
+				Err:      scanner.ErrorList{{Pos: pos, Msg: "expected 'IDENT'"}},
+			},
+			want: "a.go: formatting: expected 'IDENT'",
+		},
+		{
+			name: "without position",
+			err:  &FormatError{FilePath: "a.go", Err: errors.New("some error")},
+			want: "a.go: formatting: some error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigError_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *ConfigError
+		want string
+	}{
+		{
+			name: "with file",
+			err:  &ConfigError{FilePath: "a.go", Err: errors.New("module is undefined")},
+			want: "a.go: invalid configuration: module is undefined",
+		},
+		{
+			name: "without file",
+			err:  &ConfigError{Err: errors.New("unknown option: 100")},
+			want: "invalid configuration: unknown option: 100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,382 @@
+package reviser
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// GroupMatcher matches imports of a category
+type GroupMatcher string
+
+const (
+	// StdGroupMatcher matches std imports
+	StdGroupMatcher GroupMatcher = "std"
+
+	// LocalGroupMatcher matches imports of local packages(see Config.LocalPkgPrefixes)
+	LocalGroupMatcher GroupMatcher = "local"
+
+	// ProjectGroupMatcher matches imports of the project
+	ProjectGroupMatcher GroupMatcher = "project"
+
+	// GeneralGroupMatcher matches all other imports, including imports of matchers which are not used by groups
+	GeneralGroupMatcher GroupMatcher = "general"
+
+	// BlankGroupMatcher matches blank imports(ex.: `_ "github.com/lib/pq"`) of all categories. The group keeps the
+	// order of the original code, because blank imports can depend on the order of their registration. If it's not
+	// used by groups, blank imports are matched by their categories.
+	BlankGroupMatcher GroupMatcher = "blank"
+)
+
+var groupMatchers = []GroupMatcher{
+	StdGroupMatcher,
+	LocalGroupMatcher,
+	ProjectGroupMatcher,
+	GeneralGroupMatcher,
+	BlankGroupMatcher,
+}
+
+// ImportGroup is a group of imports, which are matched by any of the matchers
+type ImportGroup struct {
+	Matchers []GroupMatcher
+
+	// Sort is the strategy to sort imports of the group with. If it's empty, imports are sorted by paths, like they were
+	// sorted before strategies were added(gofmt re-sorted imports by paths), except of the group with the blank
+	// matcher, which keeps the order of the code.
+	Sort SortStrategy
+
+	// SubGroups is the strategy to split imports of the group into sub-groups separated by blank lines with. If it's
+	// empty, the group is not split.
+	SubGroups SubGroupStrategy
+}
+
+func (g *ImportGroup) has(matcher GroupMatcher) bool {
+	for _, m := range g.Matchers {
+		if m == matcher {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *ImportGroup) sortStrategy() SortStrategy {
+	switch {
+	case g.Sort != "":
+		return g.Sort
+	case g.has(BlankGroupMatcher):
+		return SortBySource
+	default:
+		return SortByPath
+	}
+}
+
+// SubGroupStrategy defines how imports of a group are split into sub-groups. Sub-groups follow in the order of their
+// first imports in the sorted group.
+type SubGroupStrategy string
+
+const (
+	// SubGroupByModule splits imports by modules required by go.mod and the module of the project(ex.: `k8s.io/api`
+	// and `k8s.io/client-go` are separate sub-groups). Imports of other modules are split by orgs.
+	SubGroupByModule SubGroupStrategy = "module"
+
+	// SubGroupByHost splits imports by hosts(ex.: `k8s.io`, `cloud.google.com`)
+	SubGroupByHost SubGroupStrategy = "host"
+
+	// SubGroupByOrg splits imports by hosts and first elements of paths(ex.: `github.com/pkg`, `golang.org/x`)
+	SubGroupByOrg SubGroupStrategy = "org"
+)
+
+var subGroupStrategies = []SubGroupStrategy{
+	SubGroupByModule,
+	SubGroupByHost,
+	SubGroupByOrg,
+}
+
+// subGroupKey returns the key of the sub-group of the import path. Module paths are used by SubGroupByModule only.
+func subGroupKey(strategy SubGroupStrategy, modulePaths []string, importPath string) string {
+	if strategy == SubGroupByModule {
+		var key string
+		for _, modulePath := range modulePaths {
+			isModuleImport := importPath == modulePath || strings.HasPrefix(importPath, modulePath+"/")
+			if isModuleImport && len(modulePath) > len(key) {
+				key = modulePath
+			}
+		}
+
+		if key != "" {
+			return key
+		}
+
+		strategy = SubGroupByOrg
+	}
+
+	elements := strings.SplitN(importPath, "/", 3)
+	if strategy == SubGroupByHost || len(elements) == 1 {
+		return elements[0]
+	}
+
+	return elements[0] + "/" + elements[1]
+}
+
+// splitSubGroups splits the sorted group into sub-groups by the strategy
+func splitSubGroups(group []string, strategy SubGroupStrategy, modulePaths []string) [][]string {
+	if strategy == "" || len(group) == 0 {
+		return [][]string{group}
+	}
+
+	var (
+		keys      []string
+		subGroups = map[string][]string{}
+	)
+	for _, imprt := range group {
+		key := subGroupKey(strategy, modulePaths, skipPackageAlias(imprt))
+		if _, ok := subGroups[key]; !ok {
+			keys = append(keys, key)
+		}
+
+		subGroups[key] = append(subGroups[key], imprt)
+	}
+
+	result := make([][]string, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, subGroups[key])
+	}
+
+	return result
+}
+
+// runGroupIndexes returns indexes of groups of runs of imports(see commentsMetadata.Run) by the indexes of groups of
+// imports. The run belongs to the group of the most of its imports, ties are resolved in favour of the first group.
+func runGroupIndexes(importIndexes map[string]int, importsWithMetadata map[string]*commentsMetadata) map[int]int {
+	counts := map[int]map[int]int{}
+	for imprt, index := range importIndexes {
+		metadata := importsWithMetadata[imprt]
+		if metadata == nil || metadata.Run == 0 {
+			continue
+		}
+
+		if _, ok := counts[metadata.Run]; !ok {
+			counts[metadata.Run] = map[int]int{}
+		}
+
+		counts[metadata.Run][index]++
+	}
+
+	runIndexes := make(map[int]int, len(counts))
+	for run, indexCounts := range counts {
+		runIndex := -1
+		for index, count := range indexCounts {
+			if runIndex == -1 || count > indexCounts[runIndex] || count == indexCounts[runIndex] && index < runIndex {
+				runIndex = index
+			}
+		}
+
+		runIndexes[run] = runIndex
+	}
+
+	return runIndexes
+}
+
+// splitRuns splits the sorted group into runs of imports of the original code, runs follow in the original order.
+// Added imports and imports moved from runs of other groups join the first run of the group.
+func splitRuns(
+	group []string,
+	groupIndex int,
+	runIndexes map[int]int,
+	importsWithMetadata map[string]*commentsMetadata,
+) [][]string {
+	importRuns := make([]int, len(group))
+	firstRun := 0
+	for i, imprt := range group {
+		metadata := importsWithMetadata[imprt]
+		if metadata == nil {
+			continue
+		}
+
+		if index, ok := runIndexes[metadata.Run]; ok && index == groupIndex {
+			importRuns[i] = metadata.Run
+			if firstRun == 0 || metadata.Run < firstRun {
+				firstRun = metadata.Run
+			}
+		}
+	}
+
+	var (
+		runs    []int
+		imports = map[int][]string{}
+	)
+	for i, imprt := range group {
+		run := importRuns[i]
+		if run == 0 {
+			run = firstRun
+		}
+
+		if _, ok := imports[run]; !ok {
+			runs = append(runs, run)
+		}
+
+		imports[run] = append(imports[run], imprt)
+	}
+	sort.Ints(runs)
+
+	result := make([][]string, 0, len(runs))
+	for _, run := range runs {
+		result = append(result, imports[run])
+	}
+
+	return result
+}
+
+// ImportGroups are groups of imports in the order they are written, groups are separated by blank lines. Each matcher
+// can be used by one group only, the general matcher is required.
+type ImportGroups []ImportGroup
+
+// DefaultImportGroups are std, local, project and general groups
+var DefaultImportGroups = ImportGroups{
+	{Matchers: []GroupMatcher{StdGroupMatcher}},
+	{Matchers: []GroupMatcher{LocalGroupMatcher}},
+	{Matchers: []GroupMatcher{ProjectGroupMatcher}},
+	{Matchers: []GroupMatcher{GeneralGroupMatcher}},
+}
+
+// ParseImportGroups parses comma-separated groups of imports. Matchers of a single group are joined with "+" and they
+// can be followed by the sub-group strategy of the group after "@" and by the sort strategy of the group after
+// ":"(ex.: `std,general+project@org:natural,blank`). Empty value means default groups.
+func ParseImportGroups(value string) ImportGroups {
+	var groups ImportGroups
+	for _, group := range commaValueToSlice(value) {
+		var importGroup ImportGroup
+
+		values := strings.SplitN(group, ":", 2)
+		if len(values) == 2 {
+			importGroup.Sort = SortStrategy(strings.TrimSpace(values[1]))
+		}
+
+		values = strings.SplitN(values[0], "@", 2)
+		if len(values) == 2 {
+			importGroup.SubGroups = SubGroupStrategy(strings.TrimSpace(values[1]))
+		}
+
+		for _, matcher := range strings.Split(values[0], "+") {
+			importGroup.Matchers = append(importGroup.Matchers, GroupMatcher(strings.TrimSpace(matcher)))
+		}
+
+		groups = append(groups, importGroup)
+	}
+
+	return groups
+}
+
+// validate checks that every matcher is known and it's used by one group only, that the general matcher is used and
+// that sort and sub-group strategies are known
+func (g ImportGroups) validate() error {
+	known := make(map[GroupMatcher]struct{}, len(groupMatchers))
+	for _, matcher := range groupMatchers {
+		known[matcher] = struct{}{}
+	}
+
+	knownSubGroups := make(map[SubGroupStrategy]struct{}, len(subGroupStrategies))
+	for _, strategy := range subGroupStrategies {
+		knownSubGroups[strategy] = struct{}{}
+	}
+
+	used := map[GroupMatcher]struct{}{}
+	for _, group := range g {
+		if len(group.Matchers) == 0 {
+			return errors.New("empty import group")
+		}
+
+		if _, ok := importComparators[group.Sort]; !ok && group.Sort != "" && group.Sort != SortBySource {
+			return errors.Errorf("unknown sort strategy %q", group.Sort)
+		}
+
+		if _, ok := knownSubGroups[group.SubGroups]; !ok && group.SubGroups != "" {
+			return errors.Errorf("unknown sub-group strategy %q", group.SubGroups)
+		}
+
+		for _, matcher := range group.Matchers {
+			if _, ok := known[matcher]; !ok {
+				return errors.Errorf("unknown group matcher %q", matcher)
+			}
+
+			if _, ok := used[matcher]; ok {
+				return errors.Errorf("group matcher %q is used more than once", matcher)
+			}
+
+			used[matcher] = struct{}{}
+		}
+	}
+
+	if _, ok := used[GeneralGroupMatcher]; !ok {
+		return errors.Errorf("group matcher %q is not used", GeneralGroupMatcher)
+	}
+
+	return nil
+}
+
+// hasSubGroupsByModule reports whether any group is split into sub-groups by modules
+func (g ImportGroups) hasSubGroupsByModule() bool {
+	for _, group := range g {
+		if group.SubGroups == SubGroupByModule {
+			return true
+		}
+	}
+
+	return false
+}
+
+// index returns the index of the group of every matcher. Matchers which are not used by groups get the index of the
+// general group, except of the blank matcher.
+func (g ImportGroups) index() map[GroupMatcher]int {
+	index := map[GroupMatcher]int{}
+	for i, group := range g {
+		for _, matcher := range group.Matchers {
+			index[matcher] = i
+		}
+	}
+
+	for _, matcher := range groupMatchers {
+		if _, ok := index[matcher]; !ok && matcher != BlankGroupMatcher {
+			index[matcher] = index[GeneralGroupMatcher]
+		}
+	}
+
+	return index
+}
+
+// generatedCodeRegexp matches the comment of generated files(see https://golang.org/s/generatedcode)
+var generatedCodeRegexp = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// isGenerated reports whether the file has the comment of generated files before the package clause
+func isGenerated(f *ast.File) bool {
+	for _, commentGroup := range f.Comments {
+		if commentGroup.Pos() > f.Package {
+			break
+		}
+
+		for _, comment := range commentGroup.List {
+			if generatedCodeRegexp.MatchString(comment.Text) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// IsGeneratedFile reports whether the file is generated(ex.: by protoc, mockgen or stringer). Only the header of the
+// file is parsed.
+func IsGeneratedFile(filePath string) (bool, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), filePath, nil, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return false, newParseError(filePath, err)
+	}
+
+	return isGenerated(f), nil
+}
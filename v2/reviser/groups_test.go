@@ -0,0 +1,209 @@
+package reviser
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportGroups_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		groups  ImportGroups
+		wantErr string
+	}{
+		{
+			name:   "success with default groups",
+			groups: DefaultImportGroups,
+		},
+		{
+			name:   "success with combined groups",
+			groups: ParseImportGroups("general+project,std"),
+		},
+		{
+			name:   "success with blank group",
+			groups: ParseImportGroups("std,general,blank"),
+		},
+		{
+			name:   "success with sort strategies",
+			groups: ParseImportGroups("std:case-insensitive,general:natural,blank:path"),
+		},
+		{
+			name:   "success with sub-group strategies",
+			groups: ParseImportGroups("std@host,project@module,general@org:natural"),
+		},
+		{
+			name:    "fail with empty group",
+			groups:  append(ParseImportGroups("std,general"), ImportGroup{}),
+			wantErr: "empty import group",
+		},
+		{
+			name:    "fail with unknown matcher",
+			groups:  ParseImportGroups("std,company,general"),
+			wantErr: `unknown group matcher "company"`,
+		},
+		{
+			name:    "fail with unknown sort strategy",
+			groups:  ParseImportGroups("std:random,general"),
+			wantErr: `unknown sort strategy "random"`,
+		},
+		{
+			name:    "fail with unknown sub-group strategy",
+			groups:  ParseImportGroups("std,general@repo"),
+			wantErr: `unknown sub-group strategy "repo"`,
+		},
+		{
+			name:    "fail with repeated matcher",
+			groups:  ParseImportGroups("std,general+std"),
+			wantErr: `group matcher "std" is used more than once`,
+		},
+		{
+			name:    "fail without general matcher",
+			groups:  ParseImportGroups("std,project"),
+			wantErr: `group matcher "general" is not used`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.groups.validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestParseImportGroups(t *testing.T) {
+	want := ImportGroups{
+		{Matchers: []GroupMatcher{StdGroupMatcher}},
+		{Matchers: []GroupMatcher{GeneralGroupMatcher, ProjectGroupMatcher}, Sort: SortNatural, SubGroups: SubGroupByOrg},
+		{Matchers: []GroupMatcher{BlankGroupMatcher}, Sort: SortByPath},
+	}
+
+	assert.Equal(t, want, ParseImportGroups(" std, general+project @ org:natural ,blank:path,"))
+	assert.Nil(t, ParseImportGroups(""))
+}
+
+func TestSplitSubGroups(t *testing.T) {
+	group := []string{
+		`"cloud.google.com/go/storage"`,
+		`"github.com/go-pg/pg/v9"`,
+		`"github.com/go-pg/pg/v9/orm"`,
+		`"github.com/go-pg/zerochecker"`,
+		`"github.com/pkg/errors"`,
+		`"k8s.io/api/core/v1"`,
+		`"k8s.io/client-go/kubernetes"`,
+	}
+	modulePaths := []string{"github.com/go-pg/pg/v9", "github.com/go-pg/zerochecker", "k8s.io/api", "k8s.io/client-go"}
+
+	tests := []struct {
+		strategy SubGroupStrategy
+		want     [][]string
+	}{
+		{
+			strategy: "",
+			want:     [][]string{group},
+		},
+		{
+			strategy: SubGroupByHost,
+			want:     [][]string{group[:1], group[1:5], group[5:]},
+		},
+		{
+			strategy: SubGroupByOrg,
+			want:     [][]string{group[:1], group[1:4], group[4:5], group[5:6], group[6:]},
+		},
+		{
+			strategy: SubGroupByModule,
+			want:     [][]string{group[:1], group[1:3], group[3:4], group[4:5], group[5:6], group[6:]},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			assert.Equal(t, tt.want, splitSubGroups(group, tt.strategy, modulePaths))
+		})
+	}
+}
+
+func TestSplitRuns(t *testing.T) {
+	importsWithMetadata := map[string]*commentsMetadata{
+		`"github.com/go-pg/pg/v9"`:             {Run: 3},
+		`"github.com/pkg/errors"`:              {Run: 2},
+		`"github.com/stretchr/testify/assert"`: {Run: 4},
+		`"golang.org/x/mod/modfile"`:           {},
+	}
+	group := []string{
+		`"github.com/go-pg/pg/v9"`,
+		`"github.com/pkg/errors"`,
+		`"github.com/stretchr/testify/assert"`,
+		`"golang.org/x/mod/modfile"`,
+	}
+
+	// the run 2 belongs to the other group, so its import joins the first run of the group as well as the added one
+	got := splitRuns(group, 1, map[int]int{2: 0, 3: 1, 4: 1}, importsWithMetadata)
+
+	assert.Equal(t, [][]string{
+		{`"github.com/go-pg/pg/v9"`, `"github.com/pkg/errors"`, `"golang.org/x/mod/modfile"`},
+		{`"github.com/stretchr/testify/assert"`},
+	}, got)
+}
+
+func TestIsGenerated(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want bool
+	}{
+		{
+			name: "generated",
+			src: `// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: api.proto
+
+package api
+`,
+			want: true,
+		},
+		{
+			name: "generated after build constraints",
+			src: `//go:build linux
+
+// Code generated by "stringer -type=Kind"; DO NOT EDIT.
+
+package api
+`,
+			want: true,
+		},
+		{
+			name: "comment after package clause",
+			src: `package api
+
+// Code generated by hand. DO NOT EDIT.
+`,
+			want: false,
+		},
+		{
+			name: "comment without the suffix",
+			src: `// Code generated by protoc-gen-go.
+
+package api
+`,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := parser.ParseFile(token.NewFileSet(), "", tt.src, parser.PackageClauseOnly|parser.ParseComments)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, isGenerated(f))
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package reviser
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+const (
+	// ignoreFileDirective skips revising of the file, it's returned as is
+	ignoreFileDirective = "//reviser:ignore-file"
+
+	// keepDirective in the doc or the comment of an import keeps the import as it's written: it's never removed as
+	// unused, merged with other imports or renamed
+	keepDirective = "//reviser:keep"
+
+	// offDirective and onDirective delimit the region of the import block, which is kept as it's written(see
+	// importRegion)
+	offDirective = "//reviser:off"
+	onDirective  = "//reviser:on"
+)
+
+// isFileIgnored reports whether the file has the `//reviser:ignore-file` directive
+func isFileIgnored(f *ast.File) bool {
+	return findDirective(f, ignoreFileDirective) != nil
+}
+
+// hasKeepDirective reports whether any of the comments of the import has the `//reviser:keep` directive
+func hasKeepDirective(commentGroups ...*ast.CommentGroup) bool {
+	for _, commentGroup := range commentGroups {
+		if commentGroup == nil {
+			continue
+		}
+
+		for _, comment := range commentGroup.List {
+			if isDirectiveComment(comment, keepDirective) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// importRegion is the region of the import block between `//reviser:off` and `//reviser:on` directives(or the end of
+// the block). Imports of the region are kept like the ones with `//reviser:keep` and they are written in the original
+// order together with comments and blank lines of the region as a separate group after all other groups.
+type importRegion struct {
+	// Start and End are positions of comment groups with the directives
+	Start token.Pos
+	End   token.Pos
+
+	// Text is the code of the region as it's written
+	Text string
+}
+
+func (r *importRegion) Contains(pos token.Pos) bool {
+	return r.Start <= pos && pos < r.End
+}
+
+type importRegions []*importRegion
+
+// find returns the region which contains the position
+func (r importRegions) find(pos token.Pos) *importRegion {
+	for _, region := range r {
+		if region.Contains(pos) {
+			return region
+		}
+	}
+
+	return nil
+}
+
+// parseImportRegions returns regions of import declarations of the file. Regions without imports are skipped, so their
+// comments are treated as floating comments.
+func parseImportRegions(fset *token.FileSet, f *ast.File) importRegions {
+	var regions importRegions
+	for _, decl := range f.Decls {
+		dd, ok := decl.(*ast.GenDecl)
+		if !ok || dd.Tok != token.IMPORT || isCgoImportDecl(dd) || !dd.Lparen.IsValid() {
+			continue
+		}
+
+		var (
+			declRegions importRegions
+			region      *importRegion
+		)
+		for _, commentGroup := range f.Comments {
+			if commentGroup.Pos() < dd.Lparen || commentGroup.End() > dd.Rparen {
+				continue
+			}
+
+			for _, comment := range commentGroup.List {
+				switch {
+				case region == nil && isDirectiveComment(comment, offDirective):
+					region = &importRegion{Start: commentGroup.Pos(), End: dd.Rparen}
+				case region != nil && isDirectiveComment(comment, onDirective):
+					region.End = commentGroup.End()
+					declRegions = append(declRegions, region)
+					region = nil
+				}
+			}
+		}
+
+		if region != nil {
+			declRegions = append(declRegions, region)
+		}
+
+		for _, region := range declRegions {
+			text, ok := importRegionText(fset, f.Comments, dd.Specs, region)
+			if !ok {
+				continue
+			}
+
+			region.Text = text
+			regions = append(regions, region)
+		}
+	}
+
+	return regions
+}
+
+// importRegionText returns the code of imports and comments of the region. The code is restored line by line, so
+// blank lines are kept, but indentation is not. It's false if there are no imports in the region.
+func importRegionText(
+	fset *token.FileSet,
+	commentGroups []*ast.CommentGroup,
+	specs []ast.Spec,
+	region *importRegion,
+) (string, bool) {
+	type item struct {
+		pos, end token.Pos
+		text     string
+	}
+
+	var items []item
+	for _, spec := range specs {
+		importSpec := spec.(*ast.ImportSpec)
+		if !region.Contains(importSpec.Pos()) {
+			continue
+		}
+
+		text := importSpec.Path.Value
+		if importSpec.Name != nil {
+			text = importSpec.Name.Name + " " + text
+		}
+
+		items = append(items, item{pos: importSpec.Pos(), end: importSpec.End(), text: text})
+	}
+
+	if len(items) == 0 {
+		return "", false
+	}
+
+	for _, commentGroup := range commentGroups {
+		for _, comment := range commentGroup.List {
+			if region.Contains(comment.Pos()) {
+				items = append(items, item{pos: comment.Pos(), end: comment.End(), text: comment.Text})
+			}
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].pos < items[j].pos
+	})
+
+	var (
+		b        strings.Builder
+		prevLine int
+	)
+	for i, item := range items {
+		if i > 0 {
+			switch line := fset.Position(item.pos).Line; {
+			case line == prevLine:
+				b.WriteString(" ")
+			case line == prevLine+1:
+				b.WriteString("\n")
+			default:
+				b.WriteString("\n\n")
+			}
+		}
+
+		b.WriteString(item.text)
+		prevLine = fset.Position(item.end).Line
+	}
+
+	return b.String(), true
+}
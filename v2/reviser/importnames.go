@@ -0,0 +1,148 @@
+package reviser
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+
+	"github.com/psawicki5/goimports-reviser/v2/pkg/astutil"
+)
+
+// ImportNames counts names which import paths are referred by in files(ex.: `pb`, `apiv1` and `api` for the same
+// path). The name is the alias of the import or the name of the package, which is assumed by its path, if the import
+// has no alias.
+type ImportNames map[string]map[string]int
+
+// NameCount is a name of an import path with the number of imports which use it
+type NameCount struct {
+	Name  string
+	Count int
+}
+
+// InconsistentImport is an import path, which is imported under different names
+type InconsistentImport struct {
+	ImportPath string
+
+	// Names are names of the path ordered by the number of imports(the most used name goes first)
+	Names []*NameCount
+
+	// Proposed is the name which all imports of the path should use: the alias required by the alias policy or the
+	// most used name
+	Proposed string
+}
+
+// FileImportNames returns names of imports of the file by their paths. Blank, dot and cgo imports are skipped.
+func FileImportNames(f *ast.File) map[string]string {
+	names := make(map[string]string, len(f.Imports))
+	for _, importSpec := range f.Imports {
+		importPath := strings.Trim(importSpec.Path.Value, `"`)
+		if importPath == cgoImportPath {
+			continue
+		}
+
+		name := astutil.ImportPathToAssumedName(importPath)
+		if importSpec.Name != nil {
+			name = importSpec.Name.Name
+		}
+
+		if name == "_" || name == "." {
+			continue
+		}
+
+		names[importPath] = name
+	}
+
+	return names
+}
+
+// Add counts names of imports of the file
+func (n ImportNames) Add(f *ast.File) {
+	for importPath, name := range FileImportNames(f) {
+		if n[importPath] == nil {
+			n[importPath] = map[string]int{}
+		}
+
+		n[importPath][name]++
+	}
+}
+
+// Inconsistencies returns import paths which are imported under different names ordered by paths. Paths which are
+// covered by the policy are reported if they are imported under names which differ from the required alias.
+func (n ImportNames) Inconsistencies(policy AliasPolicy) ([]*InconsistentImport, error) {
+	rules, err := compileAliasPolicy(policy)
+	if err != nil {
+		return nil, &ConfigError{Err: err}
+	}
+
+	var result []*InconsistentImport
+	for importPath, counts := range n {
+		canonical, hasCanonical := canonicalAlias(rules, importPath)
+		if hasCanonical && !token.IsIdentifier(canonical) {
+			hasCanonical = false
+		}
+
+		if len(counts) < 2 && !hasCanonical {
+			continue
+		}
+
+		if _, ok := counts[canonical]; ok && len(counts) == 1 {
+			continue
+		}
+
+		inconsistent := &InconsistentImport{ImportPath: importPath}
+		for name, count := range counts {
+			inconsistent.Names = append(inconsistent.Names, &NameCount{Name: name, Count: count})
+		}
+
+		assumedName := astutil.ImportPathToAssumedName(importPath)
+		sort.Slice(inconsistent.Names, func(i, j int) bool {
+			a, b := inconsistent.Names[i], inconsistent.Names[j]
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+
+			// the name of the package wins a tie, because imports without aliases use it
+			if (a.Name == assumedName) != (b.Name == assumedName) {
+				return a.Name == assumedName
+			}
+
+			return a.Name < b.Name
+		})
+
+		inconsistent.Proposed = inconsistent.Names[0].Name
+		if hasCanonical {
+			inconsistent.Proposed = canonical
+		}
+
+		result = append(result, inconsistent)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ImportPath < result[j].ImportPath
+	})
+
+	return result, nil
+}
+
+// ProposedAliases returns the alias policy which requires the proposed names for the inconsistent import paths
+func ProposedAliases(inconsistencies []*InconsistentImport) AliasPolicy {
+	policy := make(AliasPolicy, len(inconsistencies))
+	for _, inconsistent := range inconsistencies {
+		policy[inconsistent.ImportPath] = inconsistent.Proposed
+	}
+
+	return policy
+}
+
+// HasOtherNames reports whether any of the import names of a file(see FileImportNames) differs from the proposed name
+// of its path
+func HasOtherNames(names map[string]string, inconsistencies []*InconsistentImport) bool {
+	for _, inconsistent := range inconsistencies {
+		if name, ok := names[inconsistent.ImportPath]; ok && name != inconsistent.Proposed {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,227 @@
+package reviser
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportNames_Inconsistencies(t *testing.T) {
+	files := []string{
+		`package a
+
+import (
+	"fmt"
+	_ "net/http/pprof"
+
+	pb "example.com/api/v1"
+	"github.com/pkg/errors"
+)
+`,
+		`package a
+
+import (
+	pb "example.com/api/v1"
+	pkgerrors "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+`,
+		`package b
+
+import (
+	"fmt"
+
+	apiv1 "example.com/api/v1"
+	v1 "k8s.io/api/core/v1"
+)
+`,
+		`package b
+
+import (
+	"example.com/api/v1"
+	v1 "k8s.io/api/apps/v1"
+)
+`,
+	}
+
+	names := ImportNames{}
+	fset := token.NewFileSet()
+	for _, src := range files {
+		f, err := parser.ParseFile(fset, "", src, parser.ImportsOnly)
+		require.NoError(t, err)
+
+		names.Add(f)
+	}
+
+	got, err := names.Inconsistencies(AliasPolicy{"k8s.io/api/*/v1": "${group}v1"})
+	require.NoError(t, err)
+
+	want := []*InconsistentImport{
+		{
+			ImportPath: "example.com/api/v1",
+			Names:      []*NameCount{{Name: "pb", Count: 2}, {Name: "api", Count: 1}, {Name: "apiv1", Count: 1}},
+			Proposed:   "pb",
+		},
+		{
+			ImportPath: "github.com/pkg/errors",
+			Names:      []*NameCount{{Name: "errors", Count: 1}, {Name: "pkgerrors", Count: 1}},
+			Proposed:   "errors",
+		},
+		{
+			ImportPath: "k8s.io/api/apps/v1",
+			Names:      []*NameCount{{Name: "v1", Count: 1}},
+			Proposed:   "appsv1",
+		},
+		{
+			ImportPath: "k8s.io/api/core/v1",
+			Names:      []*NameCount{{Name: "corev1", Count: 1}, {Name: "v1", Count: 1}},
+			Proposed:   "corev1",
+		},
+	}
+
+	assert.Equal(t, want, got)
+}
+
+func TestImportNames_Inconsistencies_InvalidPolicy(t *testing.T) {
+	_, err := ImportNames{}.Inconsistencies(AliasPolicy{"k8s.io/api/*/v1": ""})
+
+	var configErr *ConfigError
+	assert.True(t, errors.As(err, &configErr))
+}
+
+func TestFileImportNames(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "", `package a
+
+import "C"
+
+import (
+	. "fmt"
+	_ "net/http/pprof"
+	"strings"
+
+	pb "example.com/api/v1"
+	"github.com/go-pg/pg/v9"
+)
+`, parser.ImportsOnly)
+	require.NoError(t, err)
+
+	want := map[string]string{
+		"strings":                "strings",
+		"example.com/api/v1":     "pb",
+		"github.com/go-pg/pg/v9": "pg",
+	}
+	assert.Equal(t, want, FileImportNames(f))
+}
+
+func TestImportNames_Inconsistencies_Majority(t *testing.T) {
+	tests := []struct {
+		name  string
+		names ImportNames
+		want  string
+	}{
+		{
+			name:  "the most used name",
+			names: ImportNames{"github.com/pkg/errors": {"errors": 1, "pkgerrors": 3, "perrors": 2}},
+			want:  "pkgerrors",
+		},
+		{
+			name:  "the package name wins a tie",
+			names: ImportNames{"github.com/pkg/errors": {"pkgerrors": 2, "errors": 2}},
+			want:  "errors",
+		},
+		{
+			name:  "the first name in alphabetical order wins a tie",
+			names: ImportNames{"github.com/pkg/errors": {"pkgerrors": 2, "perrors": 2, "errors": 1}},
+			want:  "perrors",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.names.Inconsistencies(nil)
+			require.NoError(t, err)
+			require.Len(t, got, 1)
+
+			assert.Equal(t, tt.want, got[0].Proposed)
+		})
+	}
+}
+
+func TestImportNames_Inconsistencies_Consistent(t *testing.T) {
+	names := ImportNames{
+		"github.com/pkg/errors": {"pkgerrors": 3},
+		"k8s.io/api/core/v1":    {"corev1": 2},
+	}
+
+	got, err := names.Inconsistencies(AliasPolicy{"k8s.io/api/*/v1": "${group}v1"})
+	require.NoError(t, err)
+
+	assert.Empty(t, got)
+}
+
+func TestProposedAliases_Fix(t *testing.T) {
+	sources := []string{
+		`package testdata
+
+import (
+	pkgerrors "github.com/pkg/errors"
+)
+
+var _ = pkgerrors.New("a")
+`,
+		`package testdata
+
+import (
+	pkgerrors "github.com/pkg/errors"
+)
+
+var _ = pkgerrors.New("b")
+`,
+		`package testdata
+
+import (
+	perrors "github.com/pkg/errors"
+)
+
+var _ = perrors.New("c")
+`,
+	}
+
+	names := ImportNames{}
+	fileNames := make([]map[string]string, 0, len(sources))
+	fset := token.NewFileSet()
+	for _, src := range sources {
+		f, err := parser.ParseFile(fset, "", src, parser.ImportsOnly)
+		require.NoError(t, err)
+
+		names.Add(f)
+		fileNames = append(fileNames, FileImportNames(f))
+	}
+
+	inconsistencies, err := names.Inconsistencies(nil)
+	require.NoError(t, err)
+
+	policy := ProposedAliases(inconsistencies)
+	assert.Equal(t, AliasPolicy{"github.com/pkg/errors": "pkgerrors"}, policy)
+
+	// only the file with the other name has to be fixed
+	assert.False(t, HasOtherNames(fileNames[0], inconsistencies))
+	assert.False(t, HasOtherNames(fileNames[1], inconsistencies))
+	assert.True(t, HasOtherNames(fileNames[2], inconsistencies))
+
+	r, err := New(Config{ProjectName: "github.com/psawicki5/goimports-reviser", AliasPolicy: policy})
+	require.NoError(t, err)
+
+	got := reviseExample(t, r, sources[2])
+	assert.Equal(t, `package testdata
+
+import (
+	pkgerrors "github.com/pkg/errors"
+)
+
+var _ = pkgerrors.New("c")
+`, string(got.Content))
+}
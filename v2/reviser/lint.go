@@ -0,0 +1,244 @@
+package reviser
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/psawicki5/goimports-reviser/v2/pkg/astutil"
+)
+
+// stdPackageNames returns names of importable std packages(ex.: `errors`, `context`, `http`)
+func stdPackageNames(stdPackages map[string]struct{}) map[string]struct{} {
+	names := make(map[string]struct{}, len(stdPackages))
+	for importPath := range stdPackages {
+		if isInternalOrVendored(importPath) {
+			continue
+		}
+
+		names[astutil.ImportPathToAssumedName(importPath)] = struct{}{}
+	}
+
+	return names
+}
+
+func isInternalOrVendored(importPath string) bool {
+	for _, elem := range strings.Split(importPath, "/") {
+		if elem == "internal" || elem == "vendor" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// aliasViolations returns rules of naming of aliases, which are violated by the alias of the import path: the alias
+// should be lowercase, it should not contain underscores and it should not shadow names of std packages(except of
+// the package itself) or predeclared identifiers
+func (r *Reviser) aliasViolations(alias, importPath string) []string {
+	var violations []string
+	if strings.ToLower(alias) != alias {
+		violations = append(violations, "should be lowercase")
+	}
+
+	if strings.Contains(alias, "_") {
+		violations = append(violations, "should not contain underscores")
+	}
+
+	if _, ok := r.stdPackageNames[alias]; ok && !r.isStdPackageNamed(importPath, alias) {
+		violations = append(violations, "shadows std package "+alias)
+	}
+
+	if types.Universe.Lookup(alias) != nil {
+		violations = append(violations, "shadows predeclared identifier "+alias)
+	}
+
+	return violations
+}
+
+// isStdPackageNamed reports whether the import path is a std package with the name
+func (r *Reviser) isStdPackageNamed(importPath, name string) bool {
+	_, ok := r.stdPackages[importPath]
+
+	return ok && astutil.ImportPathToAssumedName(importPath) == name
+}
+
+// lintAliases reports aliases of imports which violate the naming rules(see aliasViolations). If fix is set, the
+// aliases are replaced with compliant ones(see compliantAlias) together with qualifiers in the file. Imports covered by
+// the alias policy are skipped, because their aliases are configured explicitly. Imports kept by directives are
+// reported, but not fixed.
+func (r *Reviser) lintAliases(
+	fset *token.FileSet,
+	f *ast.File,
+	filePath string,
+	fix bool,
+	packageImports astutil.PackageImports,
+	importsWithMetadata map[string]*commentsMetadata,
+) []*Note {
+	imports := make([]string, 0, len(importsWithMetadata))
+	for imprt := range importsWithMetadata {
+		imports = append(imports, imprt)
+	}
+
+	sort.Slice(imports, func(i, j int) bool {
+		return importsWithMetadata[imports[i]].Pos < importsWithMetadata[imports[j]].Pos
+	})
+
+	var (
+		notes         []*Note
+		declaredNames map[string]struct{}
+	)
+	for _, imprt := range imports {
+		alias := importAlias(imprt)
+		if alias == "" || alias == "_" || alias == "." {
+			continue
+		}
+
+		importPath := skipPackageAlias(imprt)
+		if _, ok := canonicalAlias(r.aliasRules, importPath); ok {
+			continue
+		}
+
+		violations := r.aliasViolations(alias, importPath)
+		if len(violations) == 0 {
+			continue
+		}
+
+		metadata := importsWithMetadata[imprt]
+		if !fix || metadata.Keep {
+			notes = append(notes, newNote(
+				fset, filePath, metadata.Pos,
+				"alias %s of %q %s", alias, importPath, strings.Join(violations, ", "),
+			))
+			continue
+		}
+
+		if declaredNames == nil {
+			declaredNames = packageDeclaredNames(filePath, f.Name.Name)
+		}
+
+		compliant, ok := r.compliantAlias(f, alias, importPath, declaredNames, packageImports, importsWithMetadata)
+		if !ok {
+			notes = append(notes, newNote(
+				fset, filePath, metadata.Pos,
+				"alias %s of %q %s: can't find a compliant alias", alias, importPath, strings.Join(violations, ", "),
+			))
+			continue
+		}
+
+		astutil.RenameQualifier(f, alias, compliant)
+
+		delete(importsWithMetadata, imprt)
+		importsWithMetadata[aliasedImport(compliant, importPath, packageImports)] = metadata
+
+		notes = append(notes, newNote(
+			fset, filePath, metadata.Pos,
+			"alias %s of %q %s: it's replaced with %s", alias, importPath, strings.Join(violations, ", "), compliant,
+		))
+	}
+
+	return notes
+}
+
+// compliantAlias derives the alias for the import path, which follows the naming rules and doesn't conflict with
+// names of the file. Candidates are the alias itself in lowercase without underscores, the name of the package and
+// the name qualified by the parent element of the path(ex.: `pkgerrors` for `github.com/pkg/errors`), the qualified
+// name is suffixed with a number if it's taken.
+func (r *Reviser) compliantAlias(
+	f *ast.File,
+	alias, importPath string,
+	declaredNames map[string]struct{},
+	packageImports astutil.PackageImports,
+	importsWithMetadata map[string]*commentsMetadata,
+) (string, bool) {
+	isCompliant := func(candidate string) bool {
+		if !token.IsIdentifier(candidate) || len(r.aliasViolations(candidate, importPath)) > 0 {
+			return false
+		}
+
+		_, conflict := aliasConflict(f, candidate, importPath, declaredNames, packageImports, importsWithMetadata)
+
+		return !conflict
+	}
+
+	if candidate := sanitizeAlias(alias); isCompliant(candidate) {
+		return candidate, true
+	}
+
+	if name := importName(strconv.Quote(importPath), packageImports); isCompliant(name) {
+		return name, true
+	}
+
+	qualified := qualifiedAlias(importPath)
+	if qualified == "" {
+		return "", false
+	}
+
+	for i := 1; i < 10; i++ {
+		candidate := qualified
+		if i > 1 {
+			candidate += strconv.Itoa(i)
+		}
+
+		if isCompliant(candidate) {
+			return candidate, true
+		}
+	}
+
+	return "", false
+}
+
+// qualifiedAlias returns the alias made of the last two elements of the import path(ex.: `pkgerrors` for
+// `github.com/pkg/errors`, `corev1` for `k8s.io/api/core/v1`). The host isn't an element(ex.: `yamlv3` for
+// `gopkg.in/yaml.v3`) and the parent is skipped if it's the same as the name(ex.: `opentracing` for
+// `github.com/opentracing/opentracing-go`). It's empty if the path of a standard package has a single element.
+func qualifiedAlias(importPath string) string {
+	elements := strings.Split(importPath, "/")
+	if len(elements) > 1 && strings.Contains(elements[0], ".") {
+		elements = elements[1:]
+	} else if len(elements) < 2 {
+		return ""
+	}
+
+	name := trimGoAffixes(elements[len(elements)-1])
+
+	var parent string
+	if len(elements) > 1 {
+		parent = trimGoAffixes(elements[len(elements)-2])
+	}
+
+	if sanitizeAlias(parent) == sanitizeAlias(name) {
+		parent = ""
+	}
+
+	alias := sanitizeAlias(parent + name)
+	if alias == "" || !unicode.IsLetter([]rune(alias)[0]) {
+		return ""
+	}
+
+	return alias
+}
+
+// trimGoAffixes removes conventional "go-" prefix and "-go"/".go" suffix of repositories(ex.: `go-pg`, `yaml.go`)
+func trimGoAffixes(elem string) string {
+	elem = strings.TrimPrefix(elem, "go-")
+	elem = strings.TrimSuffix(elem, "-go")
+
+	return strings.TrimSuffix(elem, ".go")
+}
+
+// sanitizeAlias lowercases the alias and removes all characters except of letters and digits
+func sanitizeAlias(alias string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(alias) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,29 @@
+package reviser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQualifiedAlias(t *testing.T) {
+	tests := []struct {
+		importPath string
+		want       string
+	}{
+		{importPath: "github.com/pkg/errors", want: "pkgerrors"},
+		{importPath: "k8s.io/api/core/v1", want: "corev1"},
+		{importPath: "github.com/go-pg/pg/v9", want: "pgv9"},
+		{importPath: "github.com/opentracing/opentracing-go", want: "opentracing"},
+		{importPath: "gopkg.in/yaml.v3", want: "yamlv3"},
+		{importPath: "example.com/errors", want: "errors"},
+		{importPath: "example.com/1/2", want: ""},
+		{importPath: "errors", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.importPath, func(t *testing.T) {
+			assert.Equal(t, tt.want, qualifiedAlias(tt.importPath))
+		})
+	}
+}
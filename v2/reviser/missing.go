@@ -0,0 +1,258 @@
+package reviser
+
+import (
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/psawicki5/goimports-reviser/v2/pkg/astutil"
+	"github.com/psawicki5/goimports-reviser/v2/pkg/module"
+)
+
+// indexCache keeps indexes of packages, which can be imported by modules(std packages, packages of the module and
+// packages of its requirements), by root paths of the modules
+type indexCache struct {
+	// dir is a directory to store indexes in. Indexes are not stored if it's empty.
+	dir string
+
+	mu      sync.Mutex
+	byRoots map[string]*moduleIndex
+}
+
+// moduleIndex is the index of packages of the module
+type moduleIndex struct {
+	// stamp is the stamp of the module at the time of indexing(see moduleStamp)
+	stamp string
+	idx   *astutil.Index
+}
+
+func newIndexCache(dir string) *indexCache {
+	return &indexCache{
+		dir:     dir,
+		byRoots: map[string]*moduleIndex{},
+	}
+}
+
+func (c *indexCache) get(mod *moduleInfo) (*astutil.Index, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached, ok := c.byRoots[mod.rootPath]; ok && cached.stamp == mod.stamp {
+		return cached.idx, nil
+	}
+
+	requirements, err := module.Requirements(mod.rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	roots := []*astutil.IndexRoot{
+		{Dir: filepath.Join(build.Default.GOROOT, "src"), Key: goRootVersion(), SkipInternal: true},
+		{ImportPath: mod.name, Dir: mod.rootPath},
+	}
+
+	for _, requirement := range requirements {
+		root := &astutil.IndexRoot{ImportPath: requirement.Path, Dir: requirement.Dir, SkipInternal: true}
+		if requirement.Sum != "" {
+			root.Key = requirement.Version + " " + requirement.Sum
+		}
+
+		roots = append(roots, root)
+	}
+
+	idx := astutil.LoadIndex(c.dir, roots...)
+	if c.dir != "" {
+		// the index is only a cache, it's built again next time if it can't be saved
+		_ = idx.Save(c.dir)
+	}
+
+	c.byRoots[mod.rootPath] = &moduleIndex{stamp: mod.stamp, idx: idx}
+
+	return idx, nil
+}
+
+func (c *indexCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.byRoots = map[string]*moduleIndex{}
+}
+
+// goRootVersion returns the version of go installed to GOROOT. It's empty if it's unknown(ex.: go is built from
+// the source code).
+func goRootVersion() string {
+	data, err := ioutil.ReadFile(filepath.Join(build.Default.GOROOT, "VERSION"))
+	if err != nil {
+		return ""
+	}
+
+	return strings.SplitN(string(data), "\n", 2)[0]
+}
+
+// addMissingImports adds imports for unresolved qualifiers of the file(ex.: `strings` in `strings.ToUpper`).
+// The package is imported only if it's the only candidate, which exports all the identifiers used with the qualifier.
+// Added imports, as well as qualifiers which can't be resolved, are reported with notes.
+func (r *Reviser) addMissingImports(
+	fset *token.FileSet,
+	f *ast.File,
+	filePath string,
+	packageImports astutil.PackageImports,
+	importsWithMetadata map[string]*commentsMetadata,
+) ([]*Note, error) {
+	qualifiers := astutil.UnresolvedQualifiers(f)
+	// package imports are imports of all the files of the package, so only imports of the file resolve qualifiers
+	for imprt := range importsWithMetadata {
+		delete(qualifiers, importName(imprt, packageImports))
+	}
+
+	for qualifier := range qualifiers {
+		if types.Universe.Lookup(qualifier) != nil {
+			delete(qualifiers, qualifier)
+		}
+	}
+
+	if len(qualifiers) == 0 {
+		return nil, nil
+	}
+
+	// identifiers can be declared in other files of the package
+	for name := range packageDeclaredNames(filePath, f.Name.Name) {
+		delete(qualifiers, name)
+	}
+
+	if len(qualifiers) == 0 {
+		return nil, nil
+	}
+
+	mod, err := r.modules.get(path.Dir(filePath))
+	if err != nil {
+		return nil, &ConfigError{FilePath: filePath, Err: err}
+	}
+
+	idx, err := r.index.get(mod)
+	if err != nil {
+		return nil, &PackageLoadError{FilePath: filePath, Err: err}
+	}
+
+	names := make([]string, 0, len(qualifiers))
+	for qualifier := range qualifiers {
+		names = append(names, qualifier)
+	}
+	sort.Strings(names)
+
+	var notes []*Note
+	for _, qualifier := range names {
+		selectors := qualifiers[qualifier]
+		pos := selectors[0].Pos()
+
+		var (
+			matched   []string
+			selected  = selectorNames(selectors)
+			ownImport = ownImportPath(mod, filePath)
+		)
+		for _, candidate := range idx.Packages(qualifier) {
+			if candidate.ImportPath != ownImport && candidate.HasExports(selected...) {
+				matched = append(matched, candidate.ImportPath)
+			}
+		}
+		sort.Strings(matched)
+
+		switch len(matched) {
+		case 0:
+			notes = append(notes, newNote(
+				fset, filePath, pos,
+				"can't resolve %s: no package exports %s", qualifier, strings.Join(selected, ", "),
+			))
+		case 1:
+			imprt := strconv.Quote(matched[0])
+			// the name of the package differs from the name assumed by its path, so it's set explicitly
+			if astutil.ImportPathToAssumedName(matched[0]) != qualifier {
+				imprt = qualifier + " " + imprt
+			}
+
+			importsWithMetadata[imprt] = &commentsMetadata{}
+			notes = append(notes, newNote(fset, filePath, pos, "added %q import for %s", matched[0], qualifier))
+		default:
+			for i := range matched {
+				matched[i] = strconv.Quote(matched[i])
+			}
+
+			notes = append(notes, newNote(
+				fset, filePath, pos,
+				"ambiguous import for %s: %s", qualifier, strings.Join(matched, ", "),
+			))
+		}
+	}
+
+	return notes, nil
+}
+
+// selectorNames returns sorted unique identifiers selected by the selectors
+func selectorNames(selectors []*ast.SelectorExpr) []string {
+	unique := make(map[string]struct{}, len(selectors))
+	for _, sel := range selectors {
+		unique[sel.Sel.Name] = struct{}{}
+	}
+
+	names := make([]string, 0, len(unique))
+	for name := range unique {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// ownImportPath returns the import path of the package of the file, because the package can't import itself
+func ownImportPath(mod *moduleInfo, filePath string) string {
+	absDir, err := filepath.Abs(filepath.Dir(filePath))
+	if err != nil {
+		return ""
+	}
+
+	rel, err := filepath.Rel(mod.rootPath, absDir)
+	if err != nil {
+		return ""
+	}
+
+	return path.Join(mod.name, filepath.ToSlash(rel))
+}
+
+// packageDeclaredNames returns names of top-level declarations of other files of the package
+func packageDeclaredNames(filePath, packageName string) map[string]struct{} {
+	dir := filepath.Dir(filePath)
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	names := map[string]struct{}{}
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || name == filepath.Base(filePath) {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil || f.Name.Name != packageName {
+			continue
+		}
+
+		for declared := range astutil.DeclaredNames(f) {
+			names[declared] = struct{}{}
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,33 @@
+package reviser
+
+import (
+	"fmt"
+	"go/token"
+)
+
+// Note is a message about a change made by the reviser(or about a change which can't be made), which deserves the
+// user's attention
+type Note struct {
+	FilePath string
+	Pos      token.Position
+	Message  string
+}
+
+// String formats the note like the go toolchain does: "file:line:col: message"
+func (n *Note) String() string {
+	return errorString(n.FilePath, n.Pos, n.Message)
+}
+
+func newNote(fset *token.FileSet, filePath string, pos token.Pos, format string, args ...interface{}) *Note {
+	var position token.Position
+	if pos.IsValid() {
+		position = fset.Position(pos)
+		position.Filename = filePath
+	}
+
+	return &Note{
+		FilePath: filePath,
+		Pos:      position,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
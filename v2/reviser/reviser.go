@@ -2,6 +2,7 @@ package reviser
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"go/ast"
 	"go/format"
@@ -35,6 +36,39 @@ const (
 
 	// OptionFormat use to format the code
 	OptionFormat
+
+	// OptionAddMissingImports is an option to add imports for unresolved package qualifiers
+	OptionAddMissingImports
+
+	// OptionRemoveRedundantAliases is an option to remove aliases which are equal to names of imported packages
+	OptionRemoveRedundantAliases
+
+	// OptionLintAliases is an option to report aliases which violate naming rules: they should be lowercase without
+	// underscores and they should not shadow std packages or predeclared identifiers
+	OptionLintAliases
+
+	// OptionFixAliases is an option to replace aliases which violate naming rules(see OptionLintAliases) with compliant
+	// ones
+	OptionFixAliases
+
+	// OptionResolveNameConflicts is an option to set aliases for imports of different paths with the same name(ex.:
+	// `pkgerrors` for `github.com/pkg/errors`, if `errors` is imported too)
+	OptionResolveNameConflicts
+
+	// OptionRenameStdImports is an option to allow OptionResolveNameConflicts to set aliases for std imports too
+	OptionRenameStdImports
+
+	// OptionRemoveDotImports is an option to replace dot imports with named imports(except of files which match
+	// Config.DotImportsAllowed)
+	OptionRemoveDotImports
+
+	// OptionKeepBlankImportsInPlace is an option to keep blank imports(ex.: `_ "github.com/lib/pq"`) in their places
+	// within their groups: other imports of the groups are sorted around them
+	OptionKeepBlankImportsInPlace
+
+	// OptionPreserveSubGroups is an option to keep runs of imports separated by blank lines in the original code as
+	// sub-groups of their groups: imports are sorted within the runs and only imports of other groups are moved
+	OptionPreserveSubGroups
 )
 
 // Options is a slice of executing options
@@ -70,51 +104,534 @@ func (o Options) shouldFormat() bool {
 	return false
 }
 
-// Execute is for revise imports and format the code
+func (o Options) shouldAddMissingImports() bool {
+	for _, option := range o {
+		if option == OptionAddMissingImports {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o Options) shouldRemoveRedundantAliases() bool {
+	for _, option := range o {
+		if option == OptionRemoveRedundantAliases {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o Options) shouldLintAliases() bool {
+	for _, option := range o {
+		if option == OptionLintAliases {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o Options) shouldFixAliases() bool {
+	for _, option := range o {
+		if option == OptionFixAliases {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o Options) shouldResolveNameConflicts() bool {
+	for _, option := range o {
+		if option == OptionResolveNameConflicts {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o Options) shouldRenameStdImports() bool {
+	for _, option := range o {
+		if option == OptionRenameStdImports {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o Options) shouldRemoveDotImports() bool {
+	for _, option := range o {
+		if option == OptionRemoveDotImports {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o Options) shouldKeepBlankImportsInPlace() bool {
+	for _, option := range o {
+		if option == OptionKeepBlankImportsInPlace {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o Options) shouldPreserveSubGroups() bool {
+	for _, option := range o {
+		if option == OptionPreserveSubGroups {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Execute is for revise imports and format the code.
+//
+// If projectName is empty, all the imports, except of std ones, are imports of the project(see
+// Config.DetectProjectName to take the name from go.mod instead).
+//
+// Execute is safe for concurrent use, but every call loads all the required data from scratch. Use Reviser to revise
+// several files with the same configuration.
 func Execute(projectName, filePath, localPkgPrefixes string, options ...Option) ([]byte, bool, error) {
+	return ExecuteContext(context.Background(), projectName, filePath, localPkgPrefixes, options...)
+}
+
+// ExecuteContext is like Execute, but it stops once the ctx is done. Loading of package dependencies(required by
+// OptionRemoveUnusedImports and OptionUseAliasForVersionSuffix) is interrupted as well. In this case the returned
+// error wraps ctx.Err().
+//
+// Failures are reported with *ParseError, *PackageLoadError, *FormatError and *ConfigError, which can be checked
+// with errors.As.
+func ExecuteContext(
+	ctx context.Context,
+	projectName, filePath, localPkgPrefixes string,
+	options ...Option,
+) ([]byte, bool, error) {
+	r, err := New(Config{
+		ProjectName:      projectName,
+		LocalPkgPrefixes: localPkgPrefixes,
+		Options:          options,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	result, err := r.ReviseFile(ctx, filePath)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return result.Content, result.HasChange, nil
+}
+
+// Config is a configuration of Reviser
+type Config struct {
+	// ProjectName is a name of the project(ex.: github.com/incu6us/goimports-reviser). If it's empty, all the imports,
+	// except of std ones, are imports of the project, unless DetectProjectName is set.
+	ProjectName string
+
+	// DetectProjectName makes the name of the module which the revised file belongs to the name of the project, if
+	// ProjectName is empty. go.mod of the module is required then.
+	DetectProjectName bool
+
+	// LocalPkgPrefixes are comma-separated prefixes of local packages, which will be placed after 3rd-party group
+	LocalPkgPrefixes string
+
+	// Options are executing options
+	Options Options
+
+	// FloatingComments defines where to place comments inside of import blocks which don't belong to any import
+	FloatingComments FloatingCommentsPolicy
+
+	// IndexDir is a directory to store the index of packages in, which is used by OptionAddMissingImports. If it's
+	// empty, the index isn't stored and it's built from scratch by every Reviser.
+	IndexDir string
+
+	// AliasPolicy is a map of canonical aliases by import paths, which are set for imports of the paths
+	AliasPolicy AliasPolicy
+
+	// DotImportsAllowed are patterns of names of files(ex.: `*_test.go`), where dot imports are kept by
+	// OptionRemoveDotImports
+	DotImportsAllowed []string
+
+	// ImportGroups are groups of imports in the order they are written. If it's empty, DefaultImportGroups are used.
+	ImportGroups ImportGroups
+
+	// GeneratedImportGroups are groups of imports of generated files(ex.: `// Code generated by protoc-gen-go. DO NOT
+	// EDIT.`). If it's empty, ImportGroups are used.
+	GeneratedImportGroups ImportGroups
+}
+
+// Result is a result of revising
+type Result struct {
+	// Content is the revised and formatted code
+	Content []byte
+
+	// HasChange is true if Content differs from the original code
+	HasChange bool
+
+	// Notes are messages about changes, which aren't obvious(ex.: imports added because of compiler directives)
+	Notes []*Note
+}
+
+// Reviser revises imports and formats the code according to its configuration. It keeps the set of std packages,
+// metadata of modules, names of loaded packages and exported identifiers of packages between calls.
+//
+// Cached data lives as long as the Reviser. Data of the module(its name, requirements and the index of packages) and
+// names of imported packages are loaded again once go.mod or go.sum of the module is changed. Other changes(ex.:
+// identifiers exported by packages of the module) are not tracked, long-living Revisers should call Invalidate to pick
+// them up.
+//
+// Reviser is safe for concurrent use by multiple goroutines.
+type Reviser struct {
+	projectName       string
+	detectProjectName bool
+	localPkgPrefixes  string
+	options           Options
+	floatingComments  FloatingCommentsPolicy
+	aliasRules        []*aliasRule
+	dotImportsAllowed []string
+
+	importGroups          ImportGroups
+	generatedImportGroups ImportGroups
+
+	stdPackages     map[string]struct{}
+	stdPackageNames map[string]struct{}
+	modules         *moduleCache
+	packages        *packageCache
+	index           *indexCache
+}
+
+// New creates a Reviser with the configuration
+func New(cfg Config) (*Reviser, error) {
+	for _, option := range cfg.Options {
+		if option < OptionRemoveUnusedImports || option > OptionPreserveSubGroups {
+			return nil, &ConfigError{Err: errors.Errorf("unknown option: %d", option)}
+		}
+	}
+
+	if cfg.FloatingComments < FloatingCommentsAttach || cfg.FloatingComments > FloatingCommentsBlockTop {
+		return nil, &ConfigError{Err: errors.Errorf("unknown floating comments policy: %d", cfg.FloatingComments)}
+	}
+
+	aliasRules, err := compileAliasPolicy(cfg.AliasPolicy)
+	if err != nil {
+		return nil, &ConfigError{Err: err}
+	}
+
+	importGroups := cfg.ImportGroups
+	if len(importGroups) == 0 {
+		importGroups = DefaultImportGroups
+	}
+
+	if err := importGroups.validate(); err != nil {
+		return nil, &ConfigError{Err: errors.Wrap(err, "import groups")}
+	}
+
+	generatedImportGroups := cfg.GeneratedImportGroups
+	if len(generatedImportGroups) == 0 {
+		generatedImportGroups = importGroups
+	}
+
+	if err := generatedImportGroups.validate(); err != nil {
+		return nil, &ConfigError{Err: errors.Wrap(err, "import groups of generated files")}
+	}
+
+	return &Reviser{
+		projectName:           cfg.ProjectName,
+		detectProjectName:     cfg.DetectProjectName,
+		localPkgPrefixes:      cfg.LocalPkgPrefixes,
+		options:               append(Options(nil), cfg.Options...),
+		floatingComments:      cfg.FloatingComments,
+		aliasRules:            aliasRules,
+		dotImportsAllowed:     append([]string(nil), cfg.DotImportsAllowed...),
+		importGroups:          importGroups,
+		generatedImportGroups: generatedImportGroups,
+		stdPackages:           std.StdPackages,
+		stdPackageNames:       stdPackageNames(std.StdPackages),
+		modules:               newModuleCache(),
+		packages:              newPackageCache(),
+		index:                 newIndexCache(cfg.IndexDir),
+	}, nil
+}
+
+// Invalidate drops cached data(metadata of modules, names of loaded packages and indexes of packages), so it's loaded
+// again by next calls. Indexes stored in Config.IndexDir are kept, they are refreshed incrementally.
+func (r *Reviser) Invalidate() {
+	r.modules.clear()
+	r.packages.clear()
+	r.index.clear()
+}
+
+// ReviseFile revises the file. The file itself is not changed.
+func (r *Reviser) ReviseFile(ctx context.Context, filePath string) (*Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	originalContent, err := ioutil.ReadFile(filePath)
 	if err != nil {
-		return nil, false, errors.Wrap(err, "reading file")
+		return nil, errors.Wrap(err, "reading file")
+	}
+
+	return r.ReviseSource(ctx, filePath, originalContent)
+}
+
+// ReviseSource revises the code of the file. filePath is used to find the package and the module of the code, the
+// file itself is not read.
+func (r *Reviser) ReviseSource(ctx context.Context, filePath string, src []byte) (*Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
 	fset := token.NewFileSet()
 
-	pf, err := parser.ParseFile(fset, "", originalContent, parser.ParseComments)
+	pf, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, newParseError(filePath, err)
+	}
+
+	if isFileIgnored(pf) {
+		return &Result{Content: src}, nil
+	}
+
+	if shouldNormalizeCgoImports(pf) {
+		normalizedSrc := normalizeCgoImports(fset, pf, src)
+
+		fset = token.NewFileSet()
+
+		pf, err = parser.ParseFile(fset, "", normalizedSrc, parser.ParseComments)
+		if err != nil {
+			return nil, newParseError(filePath, err)
+		}
+	}
+
+	formattedContent, notes, err := r.revise(ctx, fset, pf, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Content:   formattedContent,
+		HasChange: !bytes.Equal(src, formattedContent),
+		Notes:     notes,
+	}, nil
+}
+
+// ReviseAST revises the parsed file(ex.: built by a code generator) and returns the formatted code. filePath is used
+// to find the package and the module of the code. The file is modified during revising, so it shouldn't be used
+// afterwards.
+func (r *Reviser) ReviseAST(ctx context.Context, fset *token.FileSet, f *ast.File, filePath string) (*Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	originalContent, err := generateFile(fset, f)
 	if err != nil {
-		return nil, false, errors.Wrap(err, "parsing file")
+		return nil, newFormatError(filePath, err)
+	}
+
+	if isFileIgnored(f) {
+		return &Result{Content: originalContent}, nil
+	}
+
+	// `import "C"` has to be moved together with its preamble, which is done on the source code
+	if shouldNormalizeCgoImports(f) {
+		return r.ReviseSource(ctx, filePath, originalContent)
+	}
+
+	formattedContent, notes, err := r.revise(ctx, fset, f, filePath)
+	if err != nil {
+		return nil, err
 	}
 
-	importsWithMetadata, err := parseImports(pf, filePath, options)
+	return &Result{
+		Content:   formattedContent,
+		HasChange: !bytes.Equal(originalContent, formattedContent),
+		Notes:     notes,
+	}, nil
+}
+
+func (r *Reviser) revise(
+	ctx context.Context,
+	fset *token.FileSet,
+	pf *ast.File,
+	filePath string,
+) ([]byte, []*Note, error) {
+	projectName, err := r.determineProjectName(filePath)
 	if err != nil {
-		return nil, false, errors.Wrap(err, "parsing import")
+		return nil, nil, err
+	}
+
+	var packageImports astutil.PackageImports
+	if r.options.shouldRemoveUnusedImports() ||
+		r.options.shouldUseAliasForVersionSuffix() ||
+		r.options.shouldRemoveRedundantAliases() ||
+		r.options.shouldFixAliases() ||
+		r.options.shouldResolveNameConflicts() ||
+		hasAliasPolicyImports(r.aliasRules, pf) {
+		packageImports, err = r.packages.load(ctx, path.Dir(filePath), r.moduleStamp(filePath), pf)
+		if err != nil {
+			return nil, nil, &PackageLoadError{FilePath: filePath, Err: err}
+		}
+	}
+
+	importsWithMetadata := parseImports(fset, pf, packageImports, r.options)
+	notes := mergeDuplicateImports(fset, pf, filePath, packageImports, importsWithMetadata)
+	notes = append(notes, applyAliasPolicy(fset, pf, filePath, r.aliasRules, packageImports, importsWithMetadata)...)
+
+	if r.options.shouldLintAliases() || r.options.shouldFixAliases() {
+		notes = append(notes, r.lintAliases(
+			fset, pf, filePath, r.options.shouldFixAliases(), packageImports, importsWithMetadata,
+		)...)
+	}
+
+	notes = append(notes, fixDirectiveImports(fset, pf, filePath, importsWithMetadata)...)
+
+	if r.options.shouldAddMissingImports() {
+		missingImportsNotes, err := r.addMissingImports(fset, pf, filePath, packageImports, importsWithMetadata)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		notes = append(notes, missingImportsNotes...)
+	}
+
+	if r.options.shouldResolveNameConflicts() {
+		conflictsNotes, err := r.resolveNameConflicts(
+			ctx, fset, pf, filePath, r.options.shouldRenameStdImports(), packageImports, importsWithMetadata,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		notes = append(notes, conflictsNotes...)
+	}
+
+	if r.options.shouldRemoveDotImports() && !r.isDotImportAllowed(filePath) {
+		dotImportsNotes, err := r.removeDotImports(ctx, fset, pf, filePath, packageImports, importsWithMetadata)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		notes = append(notes, dotImportsNotes...)
 	}
 
-	stdImports, generalImports, projectLocalPkgs, projectImports := groupImports(
+	detachedComments := attachFloatingComments(pf, importsWithMetadata)
+
+	importGroups := r.importGroups
+	if isGenerated(pf) {
+		importGroups = r.generatedImportGroups
+	}
+
+	var modulePaths []string
+	if importGroups.hasSubGroupsByModule() {
+		modulePaths, err = r.modulePaths(filePath)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	groups, regionImports := groupImports(
+		r.stdPackages,
 		projectName,
-		localPkgPrefixes,
+		r.localPkgPrefixes,
+		importGroups,
+		modulePaths,
+		r.options.shouldKeepBlankImportsInPlace(),
+		r.options.shouldPreserveSubGroups(),
 		importsWithMetadata,
 	)
 
+	// imports required by directives can be added to the file without import declarations
+	shouldAddImportDecl := !hasImportDecl(pf) && len(importsWithMetadata) > 0
+
 	decls, ok := hasMultipleImportDecls(pf)
 	if ok {
 		pf.Decls = decls
 	}
 
-	fixImports(pf, stdImports, generalImports, projectLocalPkgs, projectImports, importsWithMetadata)
+	fixImports(
+		pf,
+		groups,
+		regionImports,
+		importsWithMetadata,
+		r.floatingComments,
+		detachedComments,
+	)
 
-	formatDecls(pf, options)
+	formatDecls(pf, r.options)
 
 	fixedImportsContent, err := generateFile(fset, pf)
 	if err != nil {
-		return nil, false, errors.Wrap(err, "generating file")
+		return nil, nil, newFormatError(filePath, err)
 	}
 
-	formattedContent, err := format.Source(fixedImportsContent)
+	if shouldAddImportDecl {
+		fixedImportsContent, err = addImportDecl(
+			fixedImportsContent,
+			importsWithMetadata,
+			groups...,
+		)
+		if err != nil {
+			return nil, nil, newFormatError(filePath, err)
+		}
+	}
+
+	formattedContent, err := formatSource(fixedImportsContent)
+	if err != nil {
+		return nil, nil, newFormatError(filePath, err)
+	}
+
+	return formattedContent, notes, nil
+}
+
+func (r *Reviser) determineProjectName(filePath string) (string, error) {
+	if r.projectName != "" || !r.detectProjectName {
+		return r.projectName, nil
+	}
+
+	mod, err := r.modules.get(path.Dir(filePath))
+	if err != nil {
+		return "", &ConfigError{FilePath: filePath, Err: errors.Wrap(err, "determining project name")}
+	}
+
+	return mod.name, nil
+}
+
+// moduleStamp returns the stamp of the module of the file(see moduleInfo.stamp). It's empty if the file doesn't belong
+// to any module.
+func (r *Reviser) moduleStamp(filePath string) string {
+	mod, err := r.modules.get(path.Dir(filePath))
 	if err != nil {
-		return nil, false, errors.Wrap(err, "sourcing")
+		return ""
 	}
 
-	return formattedContent, !bytes.Equal(originalContent, formattedContent), nil
+	return mod.stamp
+}
+
+// modulePaths returns paths of the module of the file and modules required by it
+func (r *Reviser) modulePaths(filePath string) ([]string, error) {
+	mod, err := r.modules.get(path.Dir(filePath))
+	if err != nil {
+		return nil, &ConfigError{FilePath: filePath, Err: errors.Wrap(err, "splitting imports by modules")}
+	}
+
+	requiredPaths, err := r.modules.requiredPaths(mod)
+	if err != nil {
+		return nil, &ConfigError{FilePath: filePath, Err: errors.Wrap(err, "splitting imports by modules")}
+	}
+
+	return append([]string{mod.name}, requiredPaths...), nil
 }
 
 func formatDecls(f *ast.File, options Options) {
@@ -154,54 +671,107 @@ func fixCommentGroup(commentGroup *ast.CommentGroup) *ast.CommentGroup {
 }
 
 func groupImports(
+	stdPackages map[string]struct{},
 	projectName string,
 	localPkgPrefixes string,
+	importGroups ImportGroups,
+	modulePaths []string,
+	keepBlankImportsInPlace bool,
+	preserveSubGroups bool,
 	importsWithMetadata map[string]*commentsMetadata,
-) ([]string, []string, []string, []string) {
+) ([][]string, [][]string) {
 	var (
-		stdImports       []string
-		projectImports   []string
-		projectLocalPkgs []string
-		generalImports   []string
+		groups        = make([][]string, len(importGroups))
+		groupIndex    = importGroups.index()
+		importIndexes = make(map[string]int, len(importsWithMetadata))
+		regions       importRegions
+		regionImports = map[*importRegion][]string{}
 	)
 
+	_, hasBlankGroup := groupIndex[BlankGroupMatcher]
+
 	localPackagePrefixes := commaValueToSlice(localPkgPrefixes)
 
-	for imprt := range importsWithMetadata {
-		pkgWithoutAlias := skipPackageAlias(imprt)
+	for imprt, metadata := range importsWithMetadata {
+		// imports of `//reviser:off` regions are grouped by the regions, which are written as they are
+		if metadata != nil && metadata.Region != nil {
+			if _, ok := regionImports[metadata.Region]; !ok {
+				regions = append(regions, metadata.Region)
+			}
 
-		if _, ok := std.StdPackages[pkgWithoutAlias]; ok {
-			stdImports = append(stdImports, imprt)
+			regionImports[metadata.Region] = append(regionImports[metadata.Region], imprt)
 			continue
 		}
 
-		var isLocalPackageFound bool
-		for _, localPackagePrefix := range localPackagePrefixes {
-			if strings.HasPrefix(pkgWithoutAlias, localPackagePrefix) {
-				projectLocalPkgs = append(projectLocalPkgs, imprt)
-				isLocalPackageFound = true
-				break
-			}
+		matcher := importGroupMatcher(stdPackages, projectName, localPackagePrefixes, imprt)
+		if hasBlankGroup && importAlias(imprt) == "_" {
+			matcher = BlankGroupMatcher
 		}
 
-		if isLocalPackageFound {
-			continue
+		groups[groupIndex[matcher]] = append(groups[groupIndex[matcher]], imprt)
+		importIndexes[imprt] = groupIndex[matcher]
+	}
+
+	var runIndexes map[int]int
+	if preserveSubGroups {
+		runIndexes = runGroupIndexes(importIndexes, importsWithMetadata)
+	}
+
+	// sub-groups are written as separate groups
+	subGroups := make([][]string, 0, len(groups))
+	for i, group := range groups {
+		sortImports(group, importGroups[i].sortStrategy(), importsWithMetadata)
+
+		if keepBlankImportsInPlace {
+			placeBlankImports(group, importsWithMetadata)
 		}
 
-		if strings.Contains(pkgWithoutAlias, projectName) {
-			projectImports = append(projectImports, imprt)
-			continue
+		runs := [][]string{group}
+		if preserveSubGroups {
+			runs = splitRuns(group, i, runIndexes, importsWithMetadata)
 		}
 
-		generalImports = append(generalImports, imprt)
+		for _, run := range runs {
+			subGroups = append(subGroups, splitSubGroups(run, importGroups[i].SubGroups, modulePaths)...)
+		}
+	}
+
+	sort.Slice(regions, func(i, j int) bool {
+		return regions[i].Start < regions[j].Start
+	})
+
+	regionGroups := make([][]string, 0, len(regions))
+	for _, region := range regions {
+		regionGroups = append(regionGroups, regionImports[region])
+	}
+
+	return subGroups, regionGroups
+}
+
+// importGroupMatcher returns the matcher of the category of the import
+func importGroupMatcher(
+	stdPackages map[string]struct{},
+	projectName string,
+	localPackagePrefixes []string,
+	imprt string,
+) GroupMatcher {
+	pkgWithoutAlias := skipPackageAlias(imprt)
+
+	if _, ok := stdPackages[pkgWithoutAlias]; ok {
+		return StdGroupMatcher
 	}
 
-	sort.Strings(stdImports)
-	sort.Strings(generalImports)
-	sort.Strings(projectLocalPkgs)
-	sort.Strings(projectImports)
+	for _, localPackagePrefix := range localPackagePrefixes {
+		if strings.HasPrefix(pkgWithoutAlias, localPackagePrefix) {
+			return LocalGroupMatcher
+		}
+	}
+
+	if strings.Contains(pkgWithoutAlias, projectName) {
+		return ProjectGroupMatcher
+	}
 
-	return stdImports, generalImports, projectLocalPkgs, projectImports
+	return GeneralGroupMatcher
 }
 
 func commaValueToSlice(s string) []string {
@@ -240,11 +810,109 @@ func generateFile(fset *token.FileSet, f *ast.File) ([]byte, error) {
 	return buffer.Bytes(), nil
 }
 
+// formatSource formats the code like format.Source, except that imports are not sorted: imports are already sorted by
+// groups, and some of them keep the order of the original code. format.Source sorts imports, so import declarations
+// of its result are replaced with the ones printed in the final order.
+func formatSource(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	// lines between the last import and the closing parenthesis are merged like ast.SortImports does, otherwise
+	// comments after the last import lose their indentation
+	for _, decl := range f.Decls {
+		dd, ok := decl.(*ast.GenDecl)
+		if !ok || dd.Tok != token.IMPORT {
+			break
+		}
+
+		if !dd.Lparen.IsValid() || len(dd.Specs) == 0 {
+			continue
+		}
+
+		lastLine := fset.Position(dd.Specs[len(dd.Specs)-1].Pos()).Line
+		for rParenLine := fset.Position(dd.Rparen).Line; rParenLine > lastLine+1; {
+			rParenLine--
+			fset.File(dd.Rparen).MergeLine(rParenLine)
+		}
+	}
+
+	// the config of go/format, except of normalizing of number literals, which is done by format.Source
+	cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+
+	var printedBuf bytes.Buffer
+	if err := cfg.Fprint(&printedBuf, fset, f); err != nil {
+		return nil, err
+	}
+	printed := printedBuf.Bytes()
+
+	formatted, err := format.Source(printed)
+	if err != nil {
+		return nil, err
+	}
+
+	printedDecls, err := importDeclOffsets(printed)
+	if err != nil {
+		return nil, err
+	}
+
+	formattedDecls, err := importDeclOffsets(formatted)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(printedDecls) != len(formattedDecls) {
+		return nil, errors.New("import declarations are changed by formatting")
+	}
+
+	var (
+		buf  bytes.Buffer
+		last int
+	)
+	for i, offsets := range formattedDecls {
+		buf.Write(formatted[last:offsets[0]])
+		buf.Write(printed[printedDecls[i][0]:printedDecls[i][1]])
+		last = offsets[1]
+	}
+	buf.Write(formatted[last:])
+
+	return buf.Bytes(), nil
+}
+
+// importDeclOffsets returns start and end offsets of import declarations of the code
+func importDeclOffsets(src []byte) ([][2]int, error) {
+	fset := token.NewFileSet()
+
+	f, err := parser.ParseFile(fset, "", src, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var offsets [][2]int
+	for _, decl := range f.Decls {
+		dd, ok := decl.(*ast.GenDecl)
+		if !ok || dd.Tok != token.IMPORT {
+			break
+		}
+
+		offsets = append(offsets, [2]int{fset.Position(dd.Pos()).Offset, fset.Position(dd.End()).Offset})
+	}
+
+	return offsets, nil
+}
+
 func fixImports(
 	f *ast.File,
-	stdImports, generalImports, projectLocalPkgs, projectImports []string,
+	groups, regionImports [][]string,
 	commentsMetadata map[string]*commentsMetadata,
+	floatingCommentsPolicy FloatingCommentsPolicy,
+	detachedComments []*ast.CommentGroup,
 ) {
+	groups = append(append([][]string(nil), groups...), regionImports...)
+
 	var importsPositions []*importPosition
 	for _, decl := range f.Decls {
 		dd, ok := decl.(*ast.GenDecl)
@@ -252,7 +920,7 @@ func fixImports(
 			continue
 		}
 
-		if dd.Tok != token.IMPORT {
+		if dd.Tok != token.IMPORT || isCgoImportDecl(dd) {
 			continue
 		}
 
@@ -263,10 +931,15 @@ func fixImports(
 			},
 		)
 
-		dd.Specs = rebuildImports(dd.Tok, commentsMetadata, stdImports, generalImports, projectLocalPkgs, projectImports)
+		dd.Specs = rebuildImports(
+			dd.Tok,
+			commentsMetadata,
+			floatingCommentsPolicy,
+			groups...,
+		)
 	}
 
-	clearImportDocs(f, importsPositions)
+	clearImportDocs(f, importsPositions, detachedComments)
 	removeEmptyImportNode(f)
 }
 
@@ -285,12 +958,16 @@ func fixImports(
 func hasMultipleImportDecls(f *ast.File) ([]ast.Decl, bool) {
 	importSpecs := make([]ast.Spec, 0, len(f.Imports))
 	for _, importSpec := range f.Imports {
+		if isCgoImport(importSpec) {
+			continue
+		}
+
 		importSpecs = append(importSpecs, importSpec)
 	}
 
 	var (
-		hasMultipleImportDecls   bool
-		isFirstImportDeclDefined bool
+		hasMultipleImportDecls bool
+		firstImportDecl        *ast.GenDecl
 	)
 
 	decls := make([]ast.Decl, 0, len(f.Decls))
@@ -301,136 +978,119 @@ func hasMultipleImportDecls(f *ast.File) ([]ast.Decl, bool) {
 			continue
 		}
 
-		if dd.Tok != token.IMPORT {
+		// `import "C"` must stay in its own declaration
+		if dd.Tok != token.IMPORT || isCgoImportDecl(dd) {
 			decls = append(decls, dd)
 			continue
 		}
 
-		if isFirstImportDeclDefined {
+		if firstImportDecl != nil {
 			hasMultipleImportDecls = true
-			storedGenDecl := decls[len(decls)-1].(*ast.GenDecl)
-			if storedGenDecl.Tok == token.IMPORT {
-				storedGenDecl.Rparen = dd.End()
-			}
+			firstImportDecl.Rparen = dd.End()
 			continue
 		}
 
 		dd.Specs = importSpecs
 		decls = append(decls, dd)
-		isFirstImportDeclDefined = true
+		firstImportDecl = dd
 	}
 
 	return decls, hasMultipleImportDecls
 }
 
 func removeEmptyImportNode(f *ast.File) {
-	var (
-		decls      []ast.Decl
-		hasImports bool
-	)
-
+	decls := make([]ast.Decl, 0, len(f.Decls))
 	for _, decl := range f.Decls {
-		dd, ok := decl.(*ast.GenDecl)
-		if !ok {
-			decls = append(decls, decl)
-
+		if dd, ok := decl.(*ast.GenDecl); ok && dd.Tok == token.IMPORT && len(dd.Specs) == 0 {
 			continue
 		}
 
-		if dd.Tok == token.IMPORT && len(dd.Specs) > 0 {
-			hasImports = true
-
-			break
-		}
-
-		if dd.Tok != token.IMPORT {
-			decls = append(decls, decl)
-		}
+		decls = append(decls, decl)
 	}
 
-	if !hasImports {
-		f.Decls = decls
-	}
+	f.Decls = decls
 }
 
 func rebuildImports(
 	tok token.Token,
 	commentsMetadata map[string]*commentsMetadata,
-	stdImports []string,
-	generalImports []string,
-	projectLocalPkgs []string,
-	projectImports []string,
+	floatingCommentsPolicy FloatingCommentsPolicy,
+	groups ...[]string,
 ) []ast.Spec {
-	var specs []ast.Spec
+	var (
+		specs         []ast.Spec
+		blockComments string
+	)
 
-	linesCounter := len(stdImports)
-	for _, stdImport := range stdImports {
-		spec := &ast.ImportSpec{
-			Path: &ast.BasicLit{Value: importWithComment(stdImport, commentsMetadata), Kind: tok},
+	if floatingCommentsPolicy == FloatingCommentsBlockTop {
+		var imports []string
+		for _, group := range groups {
+			imports = append(imports, group...)
 		}
-		specs = append(specs, spec)
-
-		linesCounter--
 
-		if linesCounter == 0 && (len(generalImports) > 0 || len(projectLocalPkgs) > 0 || len(projectImports) > 0) {
-			spec = &ast.ImportSpec{Path: &ast.BasicLit{Value: "", Kind: token.STRING}}
-
-			specs = append(specs, spec)
-		}
+		blockComments = floatingCommentsText(imports, commentsMetadata)
 	}
 
-	linesCounter = len(projectLocalPkgs)
-	for _, projectLocalPkg := range projectLocalPkgs {
-		spec := &ast.ImportSpec{
-			Path: &ast.BasicLit{Value: importWithComment(projectLocalPkg, commentsMetadata), Kind: tok},
+	for _, group := range groups {
+		if len(group) == 0 {
+			continue
 		}
-		specs = append(specs, spec)
 
-		linesCounter--
-
-		if linesCounter == 0 && (len(projectImports) > 0 || len(generalImports) > 0) {
-			spec = &ast.ImportSpec{Path: &ast.BasicLit{Value: "", Kind: token.STRING}}
-
-			specs = append(specs, spec)
+		if len(specs) > 0 {
+			specs = append(specs, &ast.ImportSpec{Path: &ast.BasicLit{Value: "", Kind: token.STRING}})
 		}
-	}
 
-	linesCounter = len(projectImports)
-	for _, projectImport := range projectImports {
-		spec := &ast.ImportSpec{
-			Path: &ast.BasicLit{Value: importWithComment(projectImport, commentsMetadata), Kind: tok},
+		// the region is written as a whole, its comments are not floating
+		if region := importsRegion(group, commentsMetadata); region != nil {
+			specs = append(specs, &ast.ImportSpec{Path: &ast.BasicLit{Value: blockComments + region.Text, Kind: tok}})
+			blockComments = ""
+			continue
 		}
-		specs = append(specs, spec)
 
-		linesCounter--
+		var groupComments string
+		if floatingCommentsPolicy == FloatingCommentsGroupTop {
+			groupComments = floatingCommentsText(group, commentsMetadata)
+		}
 
-		if linesCounter == 0 && len(generalImports) > 0 {
-			spec = &ast.ImportSpec{Path: &ast.BasicLit{Value: "", Kind: token.STRING}}
+		for i, imprt := range group {
+			value := importWithComment(imprt, commentsMetadata, floatingCommentsPolicy == FloatingCommentsAttach)
+			if i == 0 {
+				value = blockComments + groupComments + value
+				blockComments = ""
+			}
 
-			specs = append(specs, spec)
+			specs = append(specs, &ast.ImportSpec{Path: &ast.BasicLit{Value: value, Kind: tok}})
 		}
 	}
 
-	for _, generalImport := range generalImports {
-		spec := &ast.ImportSpec{
-			Path: &ast.BasicLit{Value: importWithComment(generalImport, commentsMetadata), Kind: tok},
-		}
-		specs = append(specs, spec)
+	return specs
+}
+
+// importsRegion returns the `//reviser:off` region of the group of imports, if it's the group of the region
+func importsRegion(group []string, commentsMetadata map[string]*commentsMetadata) *importRegion {
+	metadata, ok := commentsMetadata[group[0]]
+	if !ok || metadata == nil {
+		return nil
 	}
 
-	return specs
+	return metadata.Region
 }
 
-func clearImportDocs(f *ast.File, importsPositions []*importPosition) {
+// clearImportDocs removes comments of import declarations, because they are written together with the rebuilt
+// imports. Detached comments are kept as is.
+func clearImportDocs(f *ast.File, importsPositions []*importPosition, detachedComments []*ast.CommentGroup) {
 	importsComments := make([]*ast.CommentGroup, 0, len(f.Comments))
 
+	detached := make(map[*ast.CommentGroup]struct{}, len(detachedComments))
+	for _, comment := range detachedComments {
+		detached[comment] = struct{}{}
+	}
+
 	for _, comment := range f.Comments {
-		for _, importPosition := range importsPositions {
-			if importPosition.IsInRange(comment) {
-				continue
-			}
-			importsComments = append(importsComments, comment)
+		if _, ok := detached[comment]; !ok && isInImportsRange(importsPositions, comment) {
+			continue
 		}
+		importsComments = append(importsComments, comment)
 	}
 
 	if len(f.Imports) > 0 {
@@ -438,69 +1098,167 @@ func clearImportDocs(f *ast.File, importsPositions []*importPosition) {
 	}
 }
 
-func importWithComment(imprt string, commentsMetadata map[string]*commentsMetadata) string {
-	var comment string
+func isInImportsRange(importsPositions []*importPosition, comment *ast.CommentGroup) bool {
+	for _, importPosition := range importsPositions {
+		if importPosition.IsInRange(comment) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func importWithComment(
+	imprt string,
+	commentsMetadata map[string]*commentsMetadata,
+	withFloatingComments bool,
+) string {
+	var before, doc, comment, after string
 	commentGroup, ok := commentsMetadata[imprt]
 	if ok {
+		if commentGroup != nil && commentGroup.Doc != nil && len(commentGroup.Doc.List) > 0 {
+			doc = commentGroupsText([]*ast.CommentGroup{commentGroup.Doc}) + "\n"
+		}
+
 		if commentGroup != nil && commentGroup.Comment != nil && len(commentGroup.Comment.List) > 0 {
-			comment = fmt.Sprintf("// %s", strings.ReplaceAll(commentGroup.Comment.Text(), "\n", ""))
+			comment = trailingCommentText(commentGroup.Comment)
+		}
+
+		if commentGroup != nil && withFloatingComments {
+			if len(commentGroup.FloatingBefore) > 0 {
+				before = commentGroupsText(commentGroup.FloatingBefore) + "\n"
+			}
+
+			if len(commentGroup.FloatingAfter) > 0 {
+				after = "\n" + commentGroupsText(commentGroup.FloatingAfter)
+			}
 		}
 	}
 
-	return fmt.Sprintf("%s %s", imprt, comment)
+	return fmt.Sprintf("%s%s%s %s%s", before, doc, imprt, comment, after)
 }
 
-func parseImports(f *ast.File, filePath string, options Options) (map[string]*commentsMetadata, error) {
+func parseImports(
+	fset *token.FileSet,
+	f *ast.File,
+	packageImports astutil.PackageImports,
+	options Options,
+) map[string]*commentsMetadata {
 	importsWithMetadata := map[string]*commentsMetadata{}
+	regions := parseImportRegions(fset, f)
 
 	shouldRemoveUnusedImports := options.shouldRemoveUnusedImports()
 	shouldUseAliasForVersionSuffix := options.shouldUseAliasForVersionSuffix()
+	shouldRemoveRedundantAliases := options.shouldRemoveRedundantAliases()
 
-	var packageImports map[string]string
-	var err error
-
-	if shouldRemoveUnusedImports || shouldUseAliasForVersionSuffix {
-		packageImports, err = astutil.LoadPackageDependencies(path.Dir(filePath), astutil.ParseBuildTag(f))
-		if err != nil {
-			return nil, errors.Wrap(err, "loading package deps")
-		}
-	}
-
+	var (
+		isFirstImportDeclDefined bool
+		run                      int
+	)
 	for _, decl := range f.Decls {
 		switch decl.(type) {
 		case *ast.GenDecl:
 			dd := decl.(*ast.GenDecl)
-			if dd.Tok == token.IMPORT {
+			if dd.Tok == token.IMPORT && !isCgoImportDecl(dd) {
+				isFirstImportDecl := !isFirstImportDeclDefined
+				isFirstImportDeclDefined = true
+
+				// every declaration starts a new run of imports, as well as every blank line between imports
+				run++
+				var prevLine int
 				for _, spec := range dd.Specs {
 					var importSpecStr string
 					importSpec := spec.(*ast.ImportSpec)
 
-					if shouldRemoveUnusedImports && !astutil.UsesImport(
+					startPos := importSpec.Pos()
+					if importSpec.Doc != nil {
+						startPos = importSpec.Doc.Pos()
+					}
+					if line := fset.Position(startPos).Line; prevLine > 0 && line > prevLine+1 {
+						run++
+					}
+					prevLine = fset.Position(importSpec.End()).Line
+
+					doc, comment := importSpec.Doc, importSpec.Comment
+					// all import declarations are combined to the first one, so the doc of a single-line declaration
+					// becomes the doc of its import
+					if doc == nil && !isFirstImportDecl && !dd.Lparen.IsValid() {
+						doc = dd.Doc
+					}
+
+					region := regions.find(importSpec.Pos())
+					// comments with the directives of the region are written with the region
+					if doc != nil && region == nil && regions.find(doc.Pos()) != nil {
+						doc = nil
+					}
+					if comment != nil && region == nil && regions.find(comment.Pos()) != nil {
+						comment = nil
+					}
+
+					keep := region != nil || hasKeepDirective(doc, comment)
+
+					if !keep && shouldRemoveUnusedImports && !astutil.UsesImport(
 						f, packageImports, strings.Trim(importSpec.Path.Value, `"`),
 					) {
 						continue
 					}
 
-					if importSpec.Name != nil {
+					if importSpec.Name != nil && (keep || !(shouldRemoveRedundantAliases &&
+						isRedundantAlias(importSpec, packageImports))) {
 						importSpecStr = strings.Join([]string{importSpec.Name.String(), importSpec.Path.Value}, " ")
 					} else {
-						if shouldUseAliasForVersionSuffix {
+						if shouldUseAliasForVersionSuffix && !keep {
 							importSpecStr = setAliasForVersionedImportSpec(importSpec, packageImports)
 						} else {
 							importSpecStr = importSpec.Path.Value
 						}
 					}
 
+					// the same import is repeated, so it's merged with the first one together with its comments. The
+					// region is written as is, so a copy outside of the region is dropped instead.
+					if metadata, ok := importsWithMetadata[importSpecStr]; ok {
+						if region == nil && metadata.Region != nil {
+							continue
+						}
+
+						if region == nil || metadata.Region != nil {
+							metadata.mergeDuplicate(doc, comment, importSpec.Pos())
+							continue
+						}
+					}
+
 					importsWithMetadata[importSpecStr] = &commentsMetadata{
-						Doc:     importSpec.Doc,
-						Comment: importSpec.Comment,
+						Doc:     doc,
+						Comment: comment,
+						Pos:     importSpec.Pos(),
+						Keep:    keep,
+						Region:  region,
+						Run:     run,
 					}
 				}
 			}
 		}
 	}
 
-	return importsWithMetadata, nil
+	return importsWithMetadata
+}
+
+// isRedundantAlias reports whether the alias of the import is equal to the name of the package, which can be
+// assumed by its path(ex.: `errors "github.com/pkg/errors"` or `pg "github.com/go-pg/pg/v9"`). The alias is kept if
+// the package can't be loaded or if its name differs from the assumed one, because the alias documents the name.
+func isRedundantAlias(importSpec *ast.ImportSpec, packageImports astutil.PackageImports) bool {
+	alias := importSpec.Name.Name
+	if alias == "_" || alias == "." {
+		return false
+	}
+
+	imprt := strings.Trim(importSpec.Path.Value, `"`)
+	name, ok := packageImports[imprt]
+	if !ok || name != alias {
+		return false
+	}
+
+	return astutil.ImportPathToAssumedName(imprt) == name
 }
 
 func setAliasForVersionedImportSpec(importSpec *ast.ImportSpec, packageImports map[string]string) string {
@@ -522,6 +1280,54 @@ func setAliasForVersionedImportSpec(importSpec *ast.ImportSpec, packageImports m
 type commentsMetadata struct {
 	Doc     *ast.CommentGroup
 	Comment *ast.CommentGroup
+
+	// Pos is the position of the import in the original code
+	Pos token.Pos
+
+	// FloatingBefore and FloatingAfter are comments inside of import declarations which don't belong to any import,
+	// but are attached to this import
+	FloatingBefore []*ast.CommentGroup
+	FloatingAfter  []*ast.CommentGroup
+
+	// Duplicates are positions of duplicates of the import, which are merged into it
+	Duplicates []token.Pos
+
+	// Keep is set for imports with `//reviser:keep` directive and imports of `//reviser:off` regions: they are never
+	// removed, merged with other imports or renamed
+	Keep bool
+
+	// Region is the `//reviser:off` region of the import, the import is written with the region
+	Region *importRegion
+
+	// Run is the number of the run of imports separated by blank lines, which the import belongs to in the original
+	// code. Runs are numbered from 1, it's 0 for added imports.
+	Run int
+}
+
+// mergeDuplicate merges the duplicate of the import. The doc of the duplicate is added to the doc of the import and
+// the trailing comment of the duplicate is added to the trailing comment of the import, so line-scoped directives(ex.:
+// "//nolint:depguard") stay on the line of the import.
+func (m *commentsMetadata) mergeDuplicate(doc, comment *ast.CommentGroup, pos token.Pos) {
+	m.Doc = mergeCommentGroups(m.Doc, doc)
+	m.Comment = mergeCommentGroups(m.Comment, comment)
+
+	m.Duplicates = append(m.Duplicates, pos)
+}
+
+// mergeCommentGroups returns a comment group with comments of both groups, it's nil if there are no comments
+func mergeCommentGroups(a, b *ast.CommentGroup) *ast.CommentGroup {
+	var list []*ast.Comment
+	for _, commentGroup := range []*ast.CommentGroup{a, b} {
+		if commentGroup != nil {
+			list = append(list, commentGroup.List...)
+		}
+	}
+
+	if len(list) == 0 {
+		return nil
+	}
+
+	return &ast.CommentGroup{List: list}
 }
 
 type importPosition struct {
@@ -1,11 +1,21 @@
 package reviser
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 
 	_ "github.com/go-pg/pg/v9"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestExecute(t *testing.T) {
@@ -212,7 +222,7 @@ import (
 		},
 
 		{
-			name: "success with clear doc for import",
+			name: "success with doc for import",
 			args: args{
 				projectName: "github.com/psawicki5/goimports-reviser",
 				filePath:    "./testdata/example.go",
@@ -234,6 +244,7 @@ import (
 import (
 	"fmt"
 
+	// test
 	"github.com/psawicki5/goimports-reviser/testdata/innderpkg"
 )
 
@@ -254,7 +265,7 @@ import (
 	"github.com/psawicki5/goimports-reviser/testdata/innderpkg" // test1
 	
 	"fmt" //test2
-	// this should be skipped
+	// this should be kept
 )
 
 // nolint:gomnd
@@ -263,7 +274,8 @@ import (
 			want: `package testdata
 
 import (
-	"fmt" // test2
+	"fmt" //test2
+	// this should be kept
 
 	"github.com/psawicki5/goimports-reviser/testdata/innderpkg" // test1
 )
@@ -357,11 +369,88 @@ import (
 			want: `package testdata
 
 import (
+	// yolo
 	"fmt"
+	// not sure why this is here but we shall find out soon enough
 	"io"
-	"sync" // test comment
+	"sync" //test comment
 	"testing"
 )
+`,
+			wantChange: true,
+			wantErr:    false,
+		},
+		{
+			name: "success with block comments and directives",
+			args: args{
+				projectName: "github.com/psawicki5/goimports-reviser",
+				filePath:    "./testdata/example.go",
+				fileContent: `package testdata
+
+import (
+	"github.com/pkg/errors" //nolint:depguard
+	"strings" /* nolint */
+	"fmt" /* first */ // second
+	"bytes" //lint:ignore SA1019 deprecated
+	"io" //test
+	"embed" /* multi
+	line */
+)
+`,
+			},
+			want: `package testdata
+
+import (
+	"bytes" //lint:ignore SA1019 deprecated
+	"embed" /* multi
+	line */
+	"fmt"     /* first */ // second
+	"io"      //test
+	"strings" /* nolint */
+
+	"github.com/pkg/errors" //nolint:depguard
+)
+`,
+			wantChange: true,
+			wantErr:    false,
+		},
+		{
+			name: "success with docs moved together with imports",
+			args: args{
+				projectName: "github.com/psawicki5/goimports-reviser",
+				filePath:    "./testdata/example.go",
+				fileContent: `package testdata
+
+import (
+	"github.com/pkg/errors"
+	// needed for pprof side effects
+	_ "net/http/pprof"
+	/*
+		block doc
+	*/
+	"fmt" // fmt comment
+	// first line
+	// second line
+	"github.com/psawicki5/goimports-reviser/testdata/innderpkg"
+)
+`,
+			},
+			want: `package testdata
+
+import (
+	/*
+		block doc
+	*/
+	"fmt" // fmt comment
+	// needed for pprof side effects
+	_ "net/http/pprof"
+
+	// first line
+	// second line
+	"github.com/psawicki5/goimports-reviser/testdata/innderpkg"
+
+	"github.com/pkg/errors"
+)
 `,
 			wantChange: true,
 			wantErr:    false,
@@ -371,6 +460,7 @@ import (
 		if err := ioutil.WriteFile(tt.args.filePath, []byte(tt.args.fileContent), 0644); err != nil {
 			t.Errorf("write test file failed: %s", err)
 		}
+		defer os.Remove(tt.args.filePath)
 
 		t.Run(tt.name, func(t *testing.T) {
 			got, hasChange, err := Execute(tt.args.projectName, tt.args.filePath, "")
@@ -420,7 +510,7 @@ func main(){
 			want: `package testdata
 
 import (
-	"fmt" // fmt package
+	"fmt" //fmt package
 )
 
 // nolint:gomnd
@@ -453,7 +543,7 @@ func main(){
 			want: `package testdata
 
 import (
-	"fmt" // fmt package
+	"fmt" //fmt package
 )
 
 // nolint:gomnd
@@ -486,9 +576,9 @@ func main(){
 			want: `package testdata
 
 import (
-	"fmt" // fmt package
+	"fmt" //fmt package
 
-	_ "github.com/pkg/errors" // custom package
+	_ "github.com/pkg/errors" //custom package
 )
 
 // nolint:gomnd
@@ -524,12 +614,45 @@ package testdata
 
 // test
 import (
-	"fmt" // fmt package
+	"fmt" //fmt package
 
-	_ "github.com/pkg/errors" // custom package
+	_ "github.com/pkg/errors" //custom package
 )
 
 // nolint:gomnd
+func main() {
+	_ = fmt.Println("test")
+}
+`,
+			wantChange: true,
+			wantErr:    false,
+		},
+		{
+			name: "remove unused import with its doc",
+			args: args{
+				projectName: "github.com/psawicki5/goimports-reviser",
+				filePath:    "./testdata/example.go",
+				fileContent: `package testdata
+
+import (
+	// errors doc
+	"github.com/pkg/errors"
+	// fmt doc
+	"fmt"
+)
+
+func main(){
+  _ = fmt.Println("test")
+}
+`,
+			},
+			want: `package testdata
+
+import (
+	// fmt doc
+	"fmt"
+)
+
 func main() {
 	_ = fmt.Println("test")
 }
@@ -615,6 +738,7 @@ func main() {
 		if err := ioutil.WriteFile(tt.args.filePath, []byte(tt.args.fileContent), 0644); err != nil {
 			t.Errorf("write test file failed: %s", err)
 		}
+		defer os.Remove(tt.args.filePath)
 
 		t.Run(tt.name, func(t *testing.T) {
 			got, hasChange, err := Execute(tt.args.projectName, tt.args.filePath, "", OptionRemoveUnusedImports)
@@ -716,6 +840,7 @@ func main() {
 		if err := ioutil.WriteFile(tt.args.filePath, []byte(tt.args.fileContent), 0644); err != nil {
 			t.Errorf("write test file failed: %s", err)
 		}
+		defer os.Remove(tt.args.filePath)
 
 		t.Run(tt.name, func(t *testing.T) {
 			got, hasChange, err := Execute(tt.args.projectName, tt.args.filePath, "", OptionUseAliasForVersionSuffix)
@@ -730,6 +855,142 @@ func main() {
 	}
 }
 
+func TestExecute_WithRemoveRedundantAliases(t *testing.T) {
+	type args struct {
+		projectName string
+		filePath    string
+		fileContent string
+		options     Options
+	}
+	tests := []struct {
+		name       string
+		args       args
+		want       string
+		wantChange bool
+		wantErr    bool
+	}{
+		{
+			name: "success with redundant aliases",
+			args: args{
+				projectName: "github.com/psawicki5/goimports-reviser",
+				filePath:    "./testdata/example.go",
+				fileContent: `package main
+import(
+	fmt "fmt"
+	errors "github.com/pkg/errors"
+	conv "strconv"
+	_ "net/http/pprof"
+)
+
+func main(){
+	_ = conv.Itoa(1)
+	fmt.Println(errors.New("test"))
+}`,
+				options: Options{OptionRemoveRedundantAliases},
+			},
+			want: `package main
+
+import (
+	"fmt"
+	_ "net/http/pprof"
+	conv "strconv"
+
+	"github.com/pkg/errors"
+)
+
+func main() {
+	_ = conv.Itoa(1)
+	fmt.Println(errors.New("test"))
+}
+`,
+			wantChange: true,
+			wantErr:    false,
+		},
+		{
+			name: "success with alias of versioned package",
+			args: args{
+				projectName: "github.com/psawicki5/goimports-reviser",
+				filePath:    "./testdata/example.go",
+				fileContent: `package main
+import(
+	"fmt"
+	pg "github.com/go-pg/pg/v9"
+)
+
+func main(){
+	fmt.Println(pg.In([]string{"test"}))
+}`,
+				options: Options{OptionRemoveRedundantAliases},
+			},
+			want: `package main
+
+import (
+	"fmt"
+
+	"github.com/go-pg/pg/v9"
+)
+
+func main() {
+	fmt.Println(pg.In([]string{"test"}))
+}
+`,
+			wantChange: true,
+			wantErr:    false,
+		},
+		{
+			name: "success with alias for version suffix",
+			args: args{
+				projectName: "github.com/psawicki5/goimports-reviser",
+				filePath:    "./testdata/example.go",
+				fileContent: `package main
+import(
+	fmt "fmt"
+	pg "github.com/go-pg/pg/v9"
+	errors "github.com/pkg/errors"
+)
+
+func main(){
+	fmt.Println(pg.In([]string{"test"}), errors.New("test"))
+}`,
+				options: Options{OptionRemoveRedundantAliases, OptionUseAliasForVersionSuffix},
+			},
+			want: `package main
+
+import (
+	"fmt"
+
+	pg "github.com/go-pg/pg/v9"
+	"github.com/pkg/errors"
+)
+
+func main() {
+	fmt.Println(pg.In([]string{"test"}), errors.New("test"))
+}
+`,
+			wantChange: true,
+			wantErr:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		if err := ioutil.WriteFile(tt.args.filePath, []byte(tt.args.fileContent), 0644); err != nil {
+			t.Errorf("write test file failed: %s", err)
+		}
+		defer os.Remove(tt.args.filePath)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, hasChange, err := Execute(tt.args.projectName, tt.args.filePath, "", tt.args.options...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, tt.wantChange, hasChange)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
 func TestExecute_WithLocalPackagePrefixes(t *testing.T) {
 	type args struct {
 		projectName      string
@@ -818,13 +1079,13 @@ func main(){
 			want: `package testdata
 
 import (
-	"fmt" // fmt package
+	"fmt" //fmt package
 
 	"goimports-reviser/pkg"
 
 	"github.com/psawicki5/goimports-reviser/pkg"
 
-	"github.com/pkg/errors" // custom package
+	"github.com/pkg/errors" //custom package
 )
 
 // nolint:gomnd
@@ -858,13 +1119,13 @@ func main(){
 			want: `package testdata
 
 import (
-	"fmt" // fmt package
+	"fmt" //fmt package
 
 	"github.com/psawicki5/goimports-reviser/pkg"
 
 	"goimports-reviser/pkg"
 
-	"github.com/pkg/errors" // custom package
+	"github.com/pkg/errors" //custom package
 )
 
 // nolint:gomnd
@@ -970,6 +1231,7 @@ func main() {
 		if err := ioutil.WriteFile(tt.args.filePath, []byte(tt.args.fileContent), 0644); err != nil {
 			t.Errorf("write test file failed: %s", err)
 		}
+		defer os.Remove(tt.args.filePath)
 
 		t.Run(tt.name, func(t *testing.T) {
 			got, hasChange, err := Execute(tt.args.projectName, tt.args.filePath, tt.args.localPkgPrefixes)
@@ -1059,6 +1321,7 @@ func test1() {}
 		if err := ioutil.WriteFile(tt.args.filePath, []byte(tt.args.fileContent), 0644); err != nil {
 			t.Errorf("write test file failed: %s", err)
 		}
+		defer os.Remove(tt.args.filePath)
 
 		t.Run(tt.name, func(t *testing.T) {
 			got, hasChange, err := Execute(tt.args.projectName, tt.args.filePath, "", OptionFormat)
@@ -1072,3 +1335,2861 @@ func test1() {}
 		})
 	}
 }
+
+func TestExecute_WithCgo(t *testing.T) {
+	type args struct {
+		projectName string
+		filePath    string
+		fileContent string
+		options     Options
+	}
+
+	tests := []struct {
+		name       string
+		args       args
+		want       string
+		wantChange bool
+		wantErr    bool
+	}{
+		{
+			name: "success with import C before other imports",
+			args: args{
+				projectName: "github.com/psawicki5/goimports-reviser",
+				filePath:    "./testdata/example.go",
+				fileContent: `package testdata
+
+// #include <stdio.h>
+//   #include <stdlib.h>
+import "C"
+
+import (
+	"strings"
+
+	"fmt"
+)
+
+func main() {
+	_ = strings.ToUpper
+	fmt.Println(C.int(1))
+}
+`,
+			},
+			want: `package testdata
+
+// #include <stdio.h>
+//   #include <stdlib.h>
+import "C"
+
+import (
+	"fmt"
+	"strings"
+)
+
+func main() {
+	_ = strings.ToUpper
+	fmt.Println(C.int(1))
+}
+`,
+			wantChange: true,
+			wantErr:    false,
+		},
+		{
+			name: "success with import C after other imports",
+			args: args{
+				projectName: "github.com/psawicki5/goimports-reviser",
+				filePath:    "./testdata/example.go",
+				fileContent: `package testdata
+
+import (
+	"strings"
+
+	"fmt"
+)
+
+/*
+#include <stdio.h>
+
+static void hello() { printf("hello"); }
+*/
+import "C"
+
+func main() {
+	_ = strings.ToUpper
+	fmt.Println(C.int(1))
+}
+`,
+			},
+			want: `package testdata
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+#include <stdio.h>
+
+static void hello() { printf("hello"); }
+*/
+import "C"
+
+func main() {
+	_ = strings.ToUpper
+	fmt.Println(C.int(1))
+}
+`,
+			wantChange: true,
+			wantErr:    false,
+		},
+		{
+			name: "success with import C between other imports",
+			args: args{
+				projectName: "github.com/psawicki5/goimports-reviser",
+				filePath:    "./testdata/example.go",
+				fileContent: `package testdata
+
+import "strings"
+
+// #cgo LDFLAGS: -lm
+// #include <math.h>
+import "C"
+
+import "fmt"
+
+func main() {
+	_ = strings.ToUpper
+	fmt.Println(C.sqrt(1))
+}
+`,
+			},
+			want: `package testdata
+
+// #cgo LDFLAGS: -lm
+// #include <math.h>
+import "C"
+
+import (
+	"fmt"
+	"strings"
+)
+
+func main() {
+	_ = strings.ToUpper
+	fmt.Println(C.sqrt(1))
+}
+`,
+			wantChange: true,
+			wantErr:    false,
+		},
+		{
+			name: "success with import C inside of import block",
+			args: args{
+				projectName: "github.com/psawicki5/goimports-reviser",
+				filePath:    "./testdata/example.go",
+				fileContent: `package testdata
+
+import (
+	"strings"
+	// #include <stdio.h>
+	"C" // cgo
+	"fmt"
+)
+
+func main() {
+	_ = strings.ToUpper
+	fmt.Println(C.int(1))
+}
+`,
+			},
+			want: `package testdata
+
+// #include <stdio.h>
+import "C" // cgo
+
+import (
+	"fmt"
+	"strings"
+)
+
+func main() {
+	_ = strings.ToUpper
+	fmt.Println(C.int(1))
+}
+`,
+			wantChange: true,
+			wantErr:    false,
+		},
+		{
+			name: "success with import C and removed unused imports",
+			args: args{
+				projectName: "github.com/psawicki5/goimports-reviser",
+				filePath:    "./testdata/example.go",
+				fileContent: `package testdata
+
+import (
+	"strings"
+)
+
+// #include <stdio.h>
+import "C"
+
+func main() {
+	_ = C.int(1)
+}
+`,
+				options: Options{OptionRemoveUnusedImports},
+			},
+			want: `package testdata
+
+// #include <stdio.h>
+import "C"
+
+func main() {
+	_ = C.int(1)
+}
+`,
+			wantChange: true,
+			wantErr:    false,
+		},
+		{
+			name: "success with import C only",
+			args: args{
+				projectName: "github.com/psawicki5/goimports-reviser",
+				filePath:    "./testdata/example.go",
+				fileContent: `package testdata
+
+// #include <stdio.h>
+import "C"
+
+func main() {
+	_ = C.int(1)
+}
+`,
+			},
+			want: `package testdata
+
+// #include <stdio.h>
+import "C"
+
+func main() {
+	_ = C.int(1)
+}
+`,
+			wantChange: false,
+			wantErr:    false,
+		},
+	}
+	for _, tt := range tests {
+		if err := ioutil.WriteFile(tt.args.filePath, []byte(tt.args.fileContent), 0644); err != nil {
+			t.Errorf("write test file failed: %s", err)
+		}
+		defer os.Remove(tt.args.filePath)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, hasChange, err := Execute(tt.args.projectName, tt.args.filePath, "", tt.args.options...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, tt.wantChange, hasChange)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestExecute_Concurrent(t *testing.T) {
+	const filesCount = 8
+
+	fileContent := `package testdata
+
+import (
+	"log"
+
+	"strings"
+
+	"bytes"
+
+	"github.com/pkg/errors"
+)
+
+func concurrent() {
+	log.Println(bytes.NewBufferString(""), errors.New("test"))
+}
+`
+	want := `package testdata
+
+import (
+	"bytes"
+	"log"
+
+	"github.com/pkg/errors"
+)
+
+func concurrent() {
+	log.Println(bytes.NewBufferString(""), errors.New("test"))
+}
+`
+
+	filePaths := make([]string, filesCount)
+	for i := range filePaths {
+		filePaths[i] = fmt.Sprintf("./testdata/example_concurrent_%d.go", i)
+		if err := ioutil.WriteFile(filePaths[i], []byte(fileContent), 0644); err != nil {
+			t.Fatalf("write test file failed: %s", err)
+		}
+	}
+
+	defer func() {
+		for _, filePath := range filePaths {
+			_ = os.Remove(filePath)
+		}
+	}()
+
+	var wg sync.WaitGroup
+	results := make([]string, filesCount)
+	errs := make([]error, filesCount)
+	for i := range filePaths {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			got, _, err := Execute("github.com/psawicki5/goimports-reviser", filePaths[i], "", OptionRemoveUnusedImports)
+			results[i], errs[i] = string(got), err
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range filePaths {
+		require.NoError(t, errs[i])
+		assert.Equal(t, want, results[i])
+	}
+}
+
+func TestExecuteContext_Canceled(t *testing.T) {
+	writeExample(t, "package testdata\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := ExecuteContext(ctx, "github.com/psawicki5/goimports-reviser", exampleFilePath, "", OptionRemoveUnusedImports)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestReviser_CanceledWhileLoading(t *testing.T) {
+	src := "package testdata\n\nimport \"fmt\"\n"
+	writeExample(t, src)
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	require.NoError(t, err)
+
+	r, err := New(Config{
+		ProjectName: "github.com/psawicki5/goimports-reviser",
+		Options:     Options{OptionRemoveUnusedImports},
+	})
+	require.NoError(t, err)
+
+	// the context is checked before revising, so revising starts with the context which is done already
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err = r.revise(ctx, fset, f, exampleFilePath)
+
+	var packageLoadErr *PackageLoadError
+	assert.True(t, errors.As(err, &packageLoadErr))
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestExecute_WithEmptyProjectName(t *testing.T) {
+	writeExample(t, `package testdata
+
+import (
+	"github.com/pkg/errors"
+	"github.com/psawicki5/goimports-reviser/testdata/innderpkg"
+	"fmt"
+)
+`)
+
+	// all the imports, except of std ones, are imports of the project
+	got, hasChange, err := Execute("", exampleFilePath, "")
+	require.NoError(t, err)
+
+	assert.True(t, hasChange)
+	assert.Equal(t, `package testdata
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/psawicki5/goimports-reviser/testdata/innderpkg"
+)
+`, string(got))
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{Options: Options{OptionRemoveUnusedImports, OptionFormat}})
+	require.NoError(t, err)
+
+	_, err = New(Config{Options: Options{Option(100)}})
+	var configErr *ConfigError
+	require.True(t, errors.As(err, &configErr))
+}
+
+func TestReviser_ReviseSource_Errors(t *testing.T) {
+	r, err := New(Config{ProjectName: "github.com/psawicki5/goimports-reviser"})
+	require.NoError(t, err)
+
+	_, err = r.ReviseSource(context.Background(), "./testdata/broken.go", []byte(`package testdata
+
+import "fmt"
+
+func main( {
+}
+`))
+
+	var parseErr *ParseError
+	require.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, "./testdata/broken.go", parseErr.FilePath)
+	assert.Equal(t, "./testdata/broken.go", parseErr.Pos.Filename)
+	assert.Equal(t, 5, parseErr.Pos.Line)
+	assert.Equal(t, 12, parseErr.Pos.Column)
+	assert.Equal(t, "./testdata/broken.go:5:12: expected ')', found '{' (and 1 more errors)", parseErr.Error())
+
+	r, err = New(Config{DetectProjectName: true})
+	require.NoError(t, err)
+
+	_, err = r.ReviseSource(context.Background(), "/", []byte("package testdata\n"))
+
+	var configErr *ConfigError
+	require.True(t, errors.As(err, &configErr))
+}
+
+func TestReviser_ReviseSource(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        Config
+		src        string
+		want       string
+		wantChange bool
+	}{
+		{
+			name: "success with project name from go.mod",
+			cfg:  Config{DetectProjectName: true},
+			src: `package testdata
+
+import (
+	"github.com/pkg/errors"
+	"github.com/psawicki5/goimports-reviser/testdata/innderpkg"
+	"fmt"
+)
+`,
+			want: `package testdata
+
+import (
+	"fmt"
+
+	"github.com/psawicki5/goimports-reviser/testdata/innderpkg"
+
+	"github.com/pkg/errors"
+)
+`,
+			wantChange: true,
+		},
+		{
+			name: "success with remove unused imports",
+			cfg: Config{
+				ProjectName: "github.com/psawicki5/goimports-reviser",
+				Options:     Options{OptionRemoveUnusedImports},
+			},
+			src: `package testdata
+
+import (
+	"fmt"
+	"strings"
+)
+
+func main() {
+	fmt.Println("test")
+}
+`,
+			want: `package testdata
+
+import (
+	"fmt"
+)
+
+func main() {
+	fmt.Println("test")
+}
+`,
+			wantChange: true,
+		},
+		{
+			name: "success with no changes",
+			cfg:  Config{ProjectName: "github.com/psawicki5/goimports-reviser"},
+			src: `package testdata
+
+import (
+	"fmt"
+)
+`,
+			want: `package testdata
+
+import (
+	"fmt"
+)
+`,
+			wantChange: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(tt.cfg)
+			require.NoError(t, err)
+
+			// the package is loaded from the directory, so it needs a file
+			writeExample(t, "package testdata\n")
+
+			// the file doesn't exist, only its directory is used to load the package
+			got, err := r.ReviseSource(context.Background(), "./testdata/not_existing.go", []byte(tt.src))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantChange, got.HasChange)
+			assert.Equal(t, tt.want, string(got.Content))
+		})
+	}
+}
+
+func TestReviser_DirectiveImports(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       Config
+		src       string
+		want      string
+		wantNotes []string
+	}{
+		{
+			name: "success with added embed import to the file without imports",
+			cfg:  Config{ProjectName: "github.com/psawicki5/goimports-reviser"},
+			src: `package testdata
+
+//go:embed hello.txt
+var hello string
+`,
+			want: `package testdata
+
+import _ "embed"
+
+//go:embed hello.txt
+var hello string
+`,
+			wantNotes: []string{
+				`testdata/example.go:3:1: added _ "embed" import required by //go:embed directive`,
+			},
+		},
+		{
+			name: "success with added embed import after the comment of the package clause",
+			cfg:  Config{ProjectName: "github.com/psawicki5/goimports-reviser"},
+			src: `package testdata // trailing
+
+//go:embed hello.txt
+var hello string
+`,
+			want: `package testdata // trailing
+
+import _ "embed"
+
+//go:embed hello.txt
+var hello string
+`,
+			wantNotes: []string{
+				`testdata/example.go:3:1: added _ "embed" import required by //go:embed directive`,
+			},
+		},
+		{
+			name: "success with added embed and unsafe imports after import C",
+			cfg:  Config{ProjectName: "github.com/psawicki5/goimports-reviser"},
+			src: `package testdata
+
+// #include <stdio.h>
+import "C"
+
+//go:embed hello.txt
+var hello string
+
+//go:linkname now time.now
+func now() (int64, int32, int64)
+`,
+			want: `package testdata
+
+// #include <stdio.h>
+import "C"
+
+import (
+	_ "embed"
+	_ "unsafe"
+)
+
+//go:embed hello.txt
+var hello string
+
+//go:linkname now time.now
+func now() (int64, int32, int64)
+`,
+			wantNotes: []string{
+				`testdata/example.go:6:1: added _ "embed" import required by //go:embed directive`,
+				`testdata/example.go:9:1: added _ "unsafe" import required by //go:linkname directive`,
+			},
+		},
+		{
+			name: "success with added embed import to the std group",
+			cfg:  Config{ProjectName: "github.com/psawicki5/goimports-reviser"},
+			src: `package testdata
+
+import (
+	"github.com/pkg/errors"
+	"fmt"
+)
+
+//go:embed hello.txt
+var hello string
+`,
+			want: `package testdata
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+//go:embed hello.txt
+var hello string
+`,
+			wantNotes: []string{
+				`testdata/example.go:8:1: added _ "embed" import required by //go:embed directive`,
+			},
+		},
+		{
+			name: "success with used embed import",
+			cfg:  Config{ProjectName: "github.com/psawicki5/goimports-reviser"},
+			src: `package testdata
+
+import "embed"
+
+//go:embed static
+var static embed.FS
+`,
+			want: `package testdata
+
+import "embed"
+
+//go:embed static
+var static embed.FS
+`,
+		},
+		{
+			name: "success with removed embed import",
+			cfg:  Config{ProjectName: "github.com/psawicki5/goimports-reviser"},
+			src: `package testdata
+
+import (
+	_ "embed"
+	"fmt"
+)
+
+var hello = fmt.Sprint("hello")
+`,
+			want: `package testdata
+
+import (
+	"fmt"
+)
+
+var hello = fmt.Sprint("hello")
+`,
+			wantNotes: []string{
+				`testdata/example.go:4:2: removed _ "embed" import: there are no //go:embed directives`,
+			},
+		},
+		{
+			name: "success with added unsafe import",
+			cfg:  Config{ProjectName: "github.com/psawicki5/goimports-reviser"},
+			src: `package testdata
+
+import (
+	"fmt"
+)
+
+//go:linkname now time.now
+func now() (int64, int32, int64)
+
+var hello = fmt.Sprint("hello")
+`,
+			want: `package testdata
+
+import (
+	"fmt"
+	_ "unsafe"
+)
+
+//go:linkname now time.now
+func now() (int64, int32, int64)
+
+var hello = fmt.Sprint("hello")
+`,
+			wantNotes: []string{
+				`testdata/example.go:7:1: added _ "unsafe" import required by //go:linkname directive`,
+			},
+		},
+		{
+			name: "success with unsafe import kept as blank import after removing unused imports",
+			cfg: Config{
+				ProjectName: "github.com/psawicki5/goimports-reviser",
+				Options:     Options{OptionRemoveUnusedImports},
+			},
+			src: `package testdata
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+//go:linkname now time.now
+func now() (int64, int32, int64)
+
+var hello = fmt.Sprint("hello")
+`,
+			want: `package testdata
+
+import (
+	"fmt"
+	_ "unsafe"
+)
+
+//go:linkname now time.now
+func now() (int64, int32, int64)
+
+var hello = fmt.Sprint("hello")
+`,
+			wantNotes: []string{
+				`testdata/example.go:8:1: added _ "unsafe" import required by //go:linkname directive`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(tt.cfg)
+			require.NoError(t, err)
+
+			got := reviseExample(t, r, tt.src)
+
+			var notes []string
+			for _, note := range got.Notes {
+				notes = append(notes, note.String())
+			}
+
+			assert.Equal(t, tt.want, string(got.Content))
+			assert.Equal(t, tt.wantNotes, notes)
+		})
+	}
+}
+
+func TestReviser_AddMissingImports(t *testing.T) {
+	// the sibling file of the same package declares the identifier, which shouldn't be resolved as a package
+	require.NoError(t, ioutil.WriteFile("testdata/example_sibling.go", []byte(`package testdata
+
+var helper struct{ Name string }
+`), 0644))
+	t.Cleanup(func() {
+		_ = os.Remove("testdata/example_sibling.go")
+	})
+
+	tests := []struct {
+		name      string
+		src       string
+		want      string
+		wantNotes []string
+	}{
+		{
+			name: "success with added std import",
+			src: `package testdata
+
+import (
+	"fmt"
+)
+
+func main() {
+	fmt.Println(strings.ToUpper("hello"), helper.Name)
+}
+`,
+			want: `package testdata
+
+import (
+	"fmt"
+	"strings"
+)
+
+func main() {
+	fmt.Println(strings.ToUpper("hello"), helper.Name)
+}
+`,
+			wantNotes: []string{
+				`testdata/example.go:8:14: added "strings" import for strings`,
+			},
+		},
+		{
+			name: "success with added imports of the module and its requirements",
+			src: `package testdata
+
+import "fmt"
+
+func main() {
+	_, ok := std.StdPackages["fmt"]
+	fmt.Println(errors.Wrap(errors.WithStack(nil), "wrapped"), ok)
+}
+`,
+			want: `package testdata
+
+import (
+	"fmt"
+
+	"github.com/psawicki5/goimports-reviser/v2/pkg/std"
+
+	"github.com/pkg/errors"
+)
+
+func main() {
+	_, ok := std.StdPackages["fmt"]
+	fmt.Println(errors.Wrap(errors.WithStack(nil), "wrapped"), ok)
+}
+`,
+			wantNotes: []string{
+				`testdata/example.go:7:14: added "github.com/pkg/errors" import for errors`,
+				`testdata/example.go:6:11: added "github.com/psawicki5/goimports-reviser/v2/pkg/std" import for std`,
+			},
+		},
+		{
+			name: "success with added import to the file without imports",
+			src: `package testdata
+
+func main() {
+	_ = strings.ToUpper("hello")
+}
+`,
+			want: `package testdata
+
+import "strings"
+
+func main() {
+	_ = strings.ToUpper("hello")
+}
+`,
+			wantNotes: []string{
+				`testdata/example.go:4:6: added "strings" import for strings`,
+			},
+		},
+		{
+			name: "success with ambiguous and unknown qualifiers",
+			src: `package testdata
+
+func main() {
+	_ = errors.New("error")
+	_ = unknown.Value
+	_ = len("local")
+}
+`,
+			want: `package testdata
+
+func main() {
+	_ = errors.New("error")
+	_ = unknown.Value
+	_ = len("local")
+}
+`,
+			wantNotes: []string{
+				`testdata/example.go:4:6: ambiguous import for errors: "errors", "github.com/pkg/errors"`,
+				`testdata/example.go:5:6: can't resolve unknown: no package exports Value`,
+			},
+		},
+		{
+			name: "success with resolved qualifiers",
+			src: `package testdata
+
+import (
+	stdstrings "strings"
+)
+
+type config struct{ name string }
+
+func main() {
+	var strings config
+	_ = strings.name
+	_ = stdstrings.ToUpper(helper.Name)
+}
+`,
+			want: `package testdata
+
+import (
+	stdstrings "strings"
+)
+
+type config struct{ name string }
+
+func main() {
+	var strings config
+	_ = strings.name
+	_ = stdstrings.ToUpper(helper.Name)
+}
+`,
+		},
+	}
+
+	r, err := New(Config{
+		ProjectName: "github.com/psawicki5/goimports-reviser",
+		Options:     Options{OptionAddMissingImports},
+	})
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reviseExample(t, r, tt.src)
+
+			var notes []string
+			for _, note := range got.Notes {
+				notes = append(notes, note.String())
+			}
+
+			assert.Equal(t, tt.want, string(got.Content))
+			assert.Equal(t, tt.wantNotes, notes)
+		})
+	}
+}
+
+func TestReviser_AddMissingImports_ImportedBySibling(t *testing.T) {
+	// the package is loaded to remove unused imports, its names of imports include imports of the sibling file
+	require.NoError(t, ioutil.WriteFile("testdata/example_sibling.go", []byte(`package testdata
+
+import "strings"
+
+var _ = strings.ToUpper
+`), 0644))
+	t.Cleanup(func() {
+		_ = os.Remove("testdata/example_sibling.go")
+	})
+
+	r, err := New(Config{
+		ProjectName: "github.com/psawicki5/goimports-reviser",
+		Options:     Options{OptionAddMissingImports, OptionRemoveUnusedImports},
+	})
+	require.NoError(t, err)
+
+	got := reviseExample(t, r, `package testdata
+
+func main() {
+	_ = strings.ToLower("HELLO")
+}
+`)
+
+	assert.Equal(t, `package testdata
+
+import "strings"
+
+func main() {
+	_ = strings.ToLower("HELLO")
+}
+`, string(got.Content))
+}
+
+func TestReviser_AddMissingImports_IndexDir(t *testing.T) {
+	indexDir := t.TempDir()
+
+	const src = `package testdata
+
+func main() {
+	_ = strings.ToUpper("hello")
+}
+`
+
+	// the second Reviser uses the index stored by the first one
+	for i := 0; i < 2; i++ {
+		r, err := New(Config{
+			ProjectName: "github.com/psawicki5/goimports-reviser",
+			Options:     Options{OptionAddMissingImports},
+			IndexDir:    indexDir,
+		})
+		require.NoError(t, err)
+
+		got := reviseExample(t, r, src)
+
+		assert.Equal(t, `package testdata
+
+import "strings"
+
+func main() {
+	_ = strings.ToUpper("hello")
+}
+`, string(got.Content))
+
+		stored, err := ioutil.ReadDir(indexDir)
+		require.NoError(t, err)
+		assert.NotEmpty(t, stored)
+	}
+}
+
+func TestReviser_DuplicateImports(t *testing.T) {
+	tests := []struct {
+		name      string
+		src       string
+		want      string
+		wantNotes []string
+	}{
+		{
+			name: "success with merged identical imports",
+			src: `package testdata
+
+import (
+	"fmt" // first
+	// doc of the duplicate
+	"fmt" // second
+)
+
+func main() {
+	fmt.Println("hello")
+}
+`,
+			want: `package testdata
+
+import (
+	// doc of the duplicate
+	"fmt" // first // second
+)
+
+func main() {
+	fmt.Println("hello")
+}
+`,
+			wantNotes: []string{
+				`testdata/example.go:6:2: merged duplicate import of "fmt"`,
+			},
+		},
+		{
+			name: "success with merged directive of the duplicate",
+			src: `package testdata
+
+import (
+	pkgerrors "github.com/pkg/errors"
+	pkgerrors "github.com/pkg/errors" //nolint:depguard
+)
+
+var _ = pkgerrors.New
+`,
+			want: `package testdata
+
+import (
+	pkgerrors "github.com/pkg/errors" //nolint:depguard
+)
+
+var _ = pkgerrors.New
+`,
+			wantNotes: []string{
+				`testdata/example.go:5:2: merged duplicate import of "github.com/pkg/errors"`,
+			},
+		},
+		{
+			name: "success with merged aliased import",
+			src: `package testdata
+
+import (
+	"fmt"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/pkg/errors"
+)
+
+func main() {
+	err := pkgerrors.New("error")
+	fmt.Println(errors.Wrap(err, "wrapped"), pkgerrors.WithStack(err))
+}
+`,
+			want: `package testdata
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+func main() {
+	err := errors.New("error")
+	fmt.Println(errors.Wrap(err, "wrapped"), errors.WithStack(err))
+}
+`,
+			wantNotes: []string{
+				`testdata/example.go:6:2: merged duplicate import of "github.com/pkg/errors": pkgerrors is replaced with errors`,
+			},
+		},
+		{
+			name: "success with merged imports with different aliases and blank import",
+			src: `package testdata
+
+import (
+	_ "github.com/go-pg/pg/v9"
+	pg1 "github.com/go-pg/pg/v9"
+	pg2 "github.com/go-pg/pg/v9"
+)
+
+func main() {
+	_, _ = pg1.In(nil), pg2.Array(nil)
+}
+`,
+			want: `package testdata
+
+import (
+	pg1 "github.com/go-pg/pg/v9"
+)
+
+func main() {
+	_, _ = pg1.In(nil), pg1.Array(nil)
+}
+`,
+			wantNotes: []string{
+				`testdata/example.go:4:2: merged duplicate import of "github.com/go-pg/pg/v9"`,
+				`testdata/example.go:6:2: merged duplicate import of "github.com/go-pg/pg/v9": pg2 is replaced with pg1`,
+			},
+		},
+		{
+			name: "success with the import name declared in the file",
+			src: `package testdata
+
+import (
+	"github.com/pkg/errors"
+	pkgerrors "github.com/pkg/errors"
+)
+
+func main() {
+	errors := []error{pkgerrors.New("error")}
+	_ = errors
+}
+`,
+			want: `package testdata
+
+import (
+	pkgerrors "github.com/pkg/errors"
+)
+
+func main() {
+	errors := []error{pkgerrors.New("error")}
+	_ = errors
+}
+`,
+			wantNotes: []string{
+				`testdata/example.go:4:2: merged duplicate import of "github.com/pkg/errors"`,
+			},
+		},
+		{
+			name: "success with unsafe merge",
+			src: `package testdata
+
+import (
+	e1 "github.com/pkg/errors"
+	e2 "github.com/pkg/errors"
+)
+
+var e1, e2 error
+
+func main() {
+	_ = e1.New("error")
+	_ = e2.WithStack(nil)
+}
+`,
+			want: `package testdata
+
+import (
+	e1 "github.com/pkg/errors"
+	e2 "github.com/pkg/errors"
+)
+
+var e1, e2 error
+
+func main() {
+	_ = e1.New("error")
+	_ = e2.WithStack(nil)
+}
+`,
+			wantNotes: []string{
+				`testdata/example.go:5:2: can't merge duplicate imports of "github.com/pkg/errors": names e1, e2 are declared or used by other imports`,
+			},
+		},
+	}
+
+	r, err := New(Config{ProjectName: "github.com/psawicki5/goimports-reviser"})
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reviseExample(t, r, tt.src)
+
+			var notes []string
+			for _, note := range got.Notes {
+				notes = append(notes, note.String())
+			}
+
+			assert.Equal(t, tt.want, string(got.Content))
+			assert.Equal(t, tt.wantNotes, notes)
+		})
+	}
+}
+
+func TestReviser_AliasPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		src       string
+		want      string
+		wantNotes []string
+	}{
+		{
+			name: "success with canonical aliases",
+			src: `package testdata
+
+import (
+	"fmt"
+
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/go-pg/pg/v9/types"
+	e "github.com/pkg/errors"
+)
+
+func main() {
+	var model orm.TableModel
+	fmt.Println(model, types.Array(nil), e.New("error"))
+}
+`,
+			want: `package testdata
+
+import (
+	"fmt"
+
+	pgorm "github.com/go-pg/pg/v9/orm"
+	pgtypes "github.com/go-pg/pg/v9/types"
+	"github.com/pkg/errors"
+)
+
+func main() {
+	var model pgorm.TableModel
+	fmt.Println(model, pgtypes.Array(nil), errors.New("error"))
+}
+`,
+		},
+		{
+			name: "success with alias declared in the file",
+			src: `package testdata
+
+import (
+	"fmt"
+
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/go-pg/pg/v9/types"
+)
+
+func main() {
+	pgorm := orm.TableModel(nil)
+	fmt.Println(pgorm, types.Array(nil))
+}
+`,
+			want: `package testdata
+
+import (
+	"fmt"
+
+	"github.com/go-pg/pg/v9/orm"
+	pgtypes "github.com/go-pg/pg/v9/types"
+)
+
+func main() {
+	pgorm := orm.TableModel(nil)
+	fmt.Println(pgorm, pgtypes.Array(nil))
+}
+`,
+			wantNotes: []string{
+				`testdata/example.go:6:2: can't set alias pgorm for "github.com/go-pg/pg/v9/orm": it's declared in the file`,
+			},
+		},
+		{
+			name: "success with alias used by another import",
+			src: `package testdata
+
+import (
+	"fmt"
+
+	"github.com/go-pg/pg/v9/orm"
+	pgorm "github.com/go-pg/pg/v9/types"
+)
+
+func main() {
+	fmt.Println(orm.TableModel(nil), pgorm.Array(nil))
+}
+`,
+			want: `package testdata
+
+import (
+	"fmt"
+
+	pgorm "github.com/go-pg/pg/v9/orm"
+	pgtypes "github.com/go-pg/pg/v9/types"
+)
+
+func main() {
+	fmt.Println(pgorm.TableModel(nil), pgtypes.Array(nil))
+}
+`,
+		},
+		{
+			name: "success with notes of several passes",
+			src: `package testdata
+
+import (
+	"github.com/go-pg/pg/v9/orm"
+	pgorm "github.com/go-pg/pg/v9/types"
+	e "github.com/pkg/errors"
+)
+
+func main() {
+	errors := orm.TableModel(nil)
+	fmt.Println(errors, pgorm.Array(nil), e.New("error"))
+}
+`,
+			want: `package testdata
+
+import (
+	pgorm "github.com/go-pg/pg/v9/orm"
+	pgtypes "github.com/go-pg/pg/v9/types"
+	e "github.com/pkg/errors"
+)
+
+func main() {
+	errors := pgorm.TableModel(nil)
+	fmt.Println(errors, pgtypes.Array(nil), e.New("error"))
+}
+`,
+			wantNotes: []string{
+				`testdata/example.go:6:2: can't set alias errors for "github.com/pkg/errors": it's declared in the file`,
+			},
+		},
+		{
+			name: "success with alias used by import of another path",
+			src: `package testdata
+
+import (
+	pgorm "fmt"
+
+	"github.com/go-pg/pg/v9/orm"
+)
+
+func main() {
+	pgorm.Println(orm.TableModel(nil))
+}
+`,
+			want: `package testdata
+
+import (
+	pgorm "fmt"
+
+	"github.com/go-pg/pg/v9/orm"
+)
+
+func main() {
+	pgorm.Println(orm.TableModel(nil))
+}
+`,
+			wantNotes: []string{
+				`testdata/example.go:6:2: can't set alias pgorm for "github.com/go-pg/pg/v9/orm": it's used by "fmt" import`,
+			},
+		},
+	}
+
+	r, err := New(Config{
+		ProjectName: "github.com/psawicki5/goimports-reviser",
+		AliasPolicy: AliasPolicy{
+			"github.com/go-pg/pg/v9/*": "pg${group}",
+			"github.com/pkg/errors":    "errors",
+		},
+	})
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reviseExample(t, r, tt.src)
+
+			var notes []string
+			for _, note := range got.Notes {
+				notes = append(notes, note.String())
+			}
+
+			assert.Equal(t, tt.want, string(got.Content))
+			assert.Equal(t, tt.wantNotes, notes)
+		})
+	}
+}
+
+func TestReviser_LintAliases(t *testing.T) {
+	src := `package testdata
+
+import (
+	"fmt"
+	string "strconv"
+
+	context "github.com/go-pg/pg/v9"
+	pg_orm "github.com/go-pg/pg/v9/orm"
+	Pkg_Errors "github.com/pkg/errors"
+)
+
+func main() {
+	var model pg_orm.TableModel
+	fmt.Println(string.Itoa(1), Pkg_Errors.New("error"), context.In(nil), model)
+}
+`
+
+	tests := []struct {
+		name      string
+		options   Options
+		want      string
+		wantNotes []string
+	}{
+		{
+			name:    "success with lint",
+			options: Options{OptionLintAliases},
+			want: `package testdata
+
+import (
+	"fmt"
+	string "strconv"
+
+	context "github.com/go-pg/pg/v9"
+	pg_orm "github.com/go-pg/pg/v9/orm"
+	Pkg_Errors "github.com/pkg/errors"
+)
+
+func main() {
+	var model pg_orm.TableModel
+	fmt.Println(string.Itoa(1), Pkg_Errors.New("error"), context.In(nil), model)
+}
+`,
+			wantNotes: []string{
+				`testdata/example.go:5:2: alias string of "strconv" shadows predeclared identifier string`,
+				`testdata/example.go:7:2: alias context of "github.com/go-pg/pg/v9" shadows std package context`,
+				`testdata/example.go:8:2: alias pg_orm of "github.com/go-pg/pg/v9/orm" should not contain underscores`,
+				`testdata/example.go:9:2: alias Pkg_Errors of "github.com/pkg/errors" should be lowercase, ` +
+					`should not contain underscores`,
+			},
+		},
+		{
+			name:    "success with fix",
+			options: Options{OptionFixAliases},
+			want: `package testdata
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/go-pg/pg/v9"
+	pgorm "github.com/go-pg/pg/v9/orm"
+	pkgerrors "github.com/pkg/errors"
+)
+
+func main() {
+	var model pgorm.TableModel
+	fmt.Println(strconv.Itoa(1), pkgerrors.New("error"), pg.In(nil), model)
+}
+`,
+			wantNotes: []string{
+				`testdata/example.go:5:2: alias string of "strconv" shadows predeclared identifier string: ` +
+					`it's replaced with strconv`,
+				`testdata/example.go:7:2: alias context of "github.com/go-pg/pg/v9" shadows std package context: ` +
+					`it's replaced with pg`,
+				`testdata/example.go:8:2: alias pg_orm of "github.com/go-pg/pg/v9/orm" should not contain underscores: ` +
+					`it's replaced with pgorm`,
+				`testdata/example.go:9:2: alias Pkg_Errors of "github.com/pkg/errors" should be lowercase, ` +
+					`should not contain underscores: it's replaced with pkgerrors`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(Config{ProjectName: "github.com/psawicki5/goimports-reviser", Options: tt.options})
+			require.NoError(t, err)
+
+			got := reviseExample(t, r, src)
+
+			var notes []string
+			for _, note := range got.Notes {
+				notes = append(notes, note.String())
+			}
+
+			assert.Equal(t, tt.want, string(got.Content))
+			assert.Equal(t, tt.wantNotes, notes)
+		})
+	}
+}
+
+func TestReviser_ResolveNameConflicts(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   Options
+		src       string
+		want      string
+		wantNotes []string
+	}{
+		{
+			name:    "success with std import",
+			options: Options{OptionResolveNameConflicts},
+			src: `package testdata
+
+import (
+	"errors"
+
+	"github.com/pkg/errors"
+)
+
+func main() {
+	_ = errors.Wrap(errors.Unwrap(nil), "wrapped")
+}
+`,
+			want: `package testdata
+
+import (
+	"errors"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+func main() {
+	_ = pkgerrors.Wrap(errors.Unwrap(nil), "wrapped")
+}
+`,
+			wantNotes: []string{
+				`testdata/example.go:6:2: "github.com/pkg/errors" is imported as pkgerrors, because errors is used by ` +
+					`other imports`,
+			},
+		},
+		{
+			name:    "success with non-std imports",
+			options: Options{OptionResolveNameConflicts},
+			src: `package testdata
+
+import (
+	"github.com/psawicki5/goimports-reviser/v2/pkg/module"
+	"golang.org/x/mod/module"
+)
+
+func main() {
+	_, _ = module.Requirements("."), module.Version{}
+	_ = module.Unknown
+}
+`,
+			want: `package testdata
+
+import (
+	"github.com/psawicki5/goimports-reviser/v2/pkg/module"
+
+	modmodule "golang.org/x/mod/module"
+)
+
+func main() {
+	_, _ = module.Requirements("."), modmodule.Version{}
+	_ = module.Unknown
+}
+`,
+			wantNotes: []string{
+				`testdata/example.go:5:2: "golang.org/x/mod/module" is imported as modmodule, because module is used ` +
+					`by other imports`,
+			},
+		},
+		{
+			name:    "success with std imports",
+			options: Options{OptionResolveNameConflicts},
+			src: `package testdata
+
+import (
+	"crypto/rand"
+	"math/rand"
+)
+
+func main() {
+	_, _ = rand.Read(nil), rand.Intn(1)
+}
+`,
+			want: `package testdata
+
+import (
+	"crypto/rand"
+	"math/rand"
+)
+
+func main() {
+	_, _ = rand.Read(nil), rand.Intn(1)
+}
+`,
+			wantNotes: []string{
+				`testdata/example.go:5:2: can't resolve conflict of imports named rand: "crypto/rand", "math/rand"`,
+			},
+		},
+		{
+			name:    "success with renamed std imports",
+			options: Options{OptionResolveNameConflicts, OptionRenameStdImports},
+			src: `package testdata
+
+import (
+	"crypto/rand"
+	"math/rand"
+)
+
+func main() {
+	_, _ = rand.Reader, rand.Intn(1)
+	_ = rand.Read(nil)
+}
+`,
+			want: `package testdata
+
+import (
+	"crypto/rand"
+	mathrand "math/rand"
+)
+
+func main() {
+	_, _ = rand.Reader, mathrand.Intn(1)
+	_ = rand.Read(nil)
+}
+`,
+			wantNotes: []string{
+				`testdata/example.go:5:2: "math/rand" is imported as mathrand, because rand is used by other imports`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(Config{ProjectName: "github.com/psawicki5/goimports-reviser", Options: tt.options})
+			require.NoError(t, err)
+
+			got := reviseExample(t, r, tt.src)
+
+			var notes []string
+			for _, note := range got.Notes {
+				notes = append(notes, note.String())
+			}
+
+			assert.Equal(t, tt.want, string(got.Content))
+			assert.Equal(t, tt.wantNotes, notes)
+		})
+	}
+}
+
+func TestReviser_RemoveDotImports(t *testing.T) {
+	tests := []struct {
+		name              string
+		dotImportsAllowed []string
+		src               string
+		want              string
+		wantNotes         []string
+	}{
+		{
+			name: "success with dot import",
+			src: `package testdata
+
+import (
+	"fmt"
+	. "strings"
+)
+
+type wrapper struct {
+	Builder
+}
+
+func main() {
+	var b Builder
+	w := wrapper{Builder: b}
+	fmt.Println(ToUpper("a"), w.Builder.Len(), Repeat(fmt.Sprint(NewReader("b").Len()), 2))
+}
+`,
+			want: `package testdata
+
+import (
+	"fmt"
+	"strings"
+)
+
+type wrapper struct {
+	strings.Builder
+}
+
+func main() {
+	var b strings.Builder
+	w := wrapper{Builder: b}
+	fmt.Println(strings.ToUpper("a"), w.Builder.Len(), strings.Repeat(fmt.Sprint(strings.NewReader("b").Len()), 2))
+}
+`,
+			wantNotes: []string{
+				`testdata/example.go:5:2: dot import of "strings" is replaced with strings`,
+			},
+		},
+		{
+			name: "success with name used by another import",
+			src: `package testdata
+
+import (
+	"errors"
+
+	. "github.com/pkg/errors"
+)
+
+func main() {
+	_ = Wrap(errors.New("error"), "wrapped")
+}
+`,
+			want: `package testdata
+
+import (
+	"errors"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+func main() {
+	_ = pkgerrors.Wrap(errors.New("error"), "wrapped")
+}
+`,
+			wantNotes: []string{
+				`testdata/example.go:6:2: dot import of "github.com/pkg/errors" is replaced with pkgerrors`,
+			},
+		},
+		{
+			name: "success with named import of the same path",
+			src: `package testdata
+
+import (
+	str "strings"
+	// dot import
+	. "strings"
+)
+
+func main() {
+	_ = str.ToLower(ToUpper("a"))
+}
+`,
+			want: `package testdata
+
+import (
+	// dot import
+	str "strings"
+)
+
+func main() {
+	_ = str.ToLower(str.ToUpper("a"))
+}
+`,
+			wantNotes: []string{
+				`testdata/example.go:6:2: dot import of "strings" is replaced with str`,
+			},
+		},
+		{
+			name:              "success with allowed dot import",
+			dotImportsAllowed: []string{"*_test.go", "example.go"},
+			src: `package testdata
+
+import (
+	. "strings"
+)
+
+func main() {
+	_ = ToUpper("a")
+}
+`,
+			want: `package testdata
+
+import (
+	. "strings"
+)
+
+func main() {
+	_ = ToUpper("a")
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(Config{
+				ProjectName:       "github.com/psawicki5/goimports-reviser",
+				Options:           Options{OptionRemoveDotImports},
+				DotImportsAllowed: tt.dotImportsAllowed,
+			})
+			require.NoError(t, err)
+
+			got := reviseExample(t, r, tt.src)
+
+			var notes []string
+			for _, note := range got.Notes {
+				notes = append(notes, note.String())
+			}
+
+			assert.Equal(t, tt.want, string(got.Content))
+			assert.Equal(t, tt.wantNotes, notes)
+		})
+	}
+}
+
+func TestReviser_RemoveDotImports_AbsolutePath(t *testing.T) {
+	src := `package testdata
+
+import (
+	. "github.com/pkg/errors"
+)
+
+func main() {
+	_ = Wrap(nil, "wrapped")
+}
+`
+	writeExample(t, src)
+
+	filePath, err := filepath.Abs(exampleFilePath)
+	require.NoError(t, err)
+
+	// the package is loaded from the directory of the file, not from the working directory
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir("/"))
+	defer func() { require.NoError(t, os.Chdir(wd)) }()
+
+	r, err := New(Config{ProjectName: "github.com/psawicki5/goimports-reviser", Options: Options{OptionRemoveDotImports}})
+	require.NoError(t, err)
+
+	got, err := r.ReviseSource(context.Background(), filePath, []byte(src))
+	require.NoError(t, err)
+
+	assert.Equal(t, `package testdata
+
+import (
+	"github.com/pkg/errors"
+)
+
+func main() {
+	_ = errors.Wrap(nil, "wrapped")
+}
+`, string(got.Content))
+}
+
+func TestReviser_RemoveDotImports_Canceled(t *testing.T) {
+	src := `package testdata
+
+import (
+	. "strings"
+)
+
+var _ = ToUpper("a")
+`
+	writeExample(t, src)
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, exampleFilePath, src, parser.ParseComments)
+	require.NoError(t, err)
+
+	r, err := New(Config{ProjectName: "github.com/psawicki5/goimports-reviser", Options: Options{OptionRemoveDotImports}})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	importsWithMetadata := map[string]*commentsMetadata{`. "strings"`: {Pos: f.Imports[0].Pos()}}
+	_, err = r.removeDotImports(ctx, fset, f, exampleFilePath, nil, importsWithMetadata)
+
+	var packageLoadErr *PackageLoadError
+	assert.True(t, errors.As(err, &packageLoadErr))
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestReviser_Directives(t *testing.T) {
+	tests := []struct {
+		name          string
+		options       Options
+		src           string
+		want          string
+		wantHasChange bool
+		wantNotes     []string
+	}{
+		{
+			name:    "success with ignored file",
+			options: Options{OptionRemoveUnusedImports},
+			src: `//reviser:ignore-file
+
+package testdata
+
+import (
+	"strings"
+	"fmt"
+)
+
+func main() {
+	fmt.Println("test")
+}
+`,
+			want: `//reviser:ignore-file
+
+package testdata
+
+import (
+	"strings"
+	"fmt"
+)
+
+func main() {
+	fmt.Println("test")
+}
+`,
+		},
+		{
+			name:    "success with kept imports",
+			options: Options{OptionRemoveUnusedImports, OptionRemoveRedundantAliases},
+			src: `package testdata
+
+import (
+	"strings"
+	//reviser:keep
+	"bytes"
+	errors "github.com/pkg/errors" //reviser:keep
+	"fmt"
+)
+
+func main() {
+	fmt.Println(errors.New("test"))
+}
+`,
+			want: `package testdata
+
+import (
+	//reviser:keep
+	"bytes"
+	"fmt"
+
+	errors "github.com/pkg/errors" //reviser:keep
+)
+
+func main() {
+	fmt.Println(errors.New("test"))
+}
+`,
+			wantHasChange: true,
+		},
+		{
+			name:    "success with region",
+			options: Options{OptionRemoveUnusedImports},
+			src: `package testdata
+
+import (
+	"github.com/pkg/errors"
+
+	// hand-ordered imports
+	//reviser:off
+	"os"
+
+	"bytes" // not used
+	"expvar"
+	//reviser:on
+
+	"strings"
+	"fmt"
+)
+
+func main() {
+	fmt.Println(errors.New("test"))
+}
+`,
+			want: `package testdata
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	// hand-ordered imports
+	//reviser:off
+	"os"
+
+	"bytes" // not used
+	"expvar"
+	//reviser:on
+)
+
+func main() {
+	fmt.Println(errors.New("test"))
+}
+`,
+			wantHasChange: true,
+		},
+		{
+			name: "success with region till the end of the block",
+			src: `package testdata
+
+import (
+	//reviser:off
+	"strings"
+	"fmt"
+
+	"bytes"
+)
+
+func main() {
+	fmt.Println(strings.ToUpper(bytes.MinRead))
+}
+`,
+			want: `package testdata
+
+import (
+	//reviser:off
+	"strings"
+	"fmt"
+
+	"bytes"
+)
+
+func main() {
+	fmt.Println(strings.ToUpper(bytes.MinRead))
+}
+`,
+		},
+		{
+			name: "success with region and number literals",
+			src: `package testdata
+
+import (
+	//reviser:off
+	"strings"
+	"fmt"
+	//reviser:on
+)
+
+func main() {
+	fmt.Println(strings.Repeat("a", 0X1F), 1E3)
+}
+`,
+			want: `package testdata
+
+import (
+	//reviser:off
+	"strings"
+	"fmt"
+	//reviser:on
+)
+
+func main() {
+	fmt.Println(strings.Repeat("a", 0x1F), 1e3)
+}
+`,
+			wantHasChange: true,
+		},
+		{
+			name: "success with import outside and inside of region",
+			src: `package testdata
+
+import (
+	"bytes" // outside
+	"fmt"
+
+	//reviser:off
+	"strings"
+	"bytes" // inside
+	//reviser:on
+)
+
+func main() {
+	fmt.Println(strings.ToUpper(bytes.MinRead))
+}
+`,
+			want: `package testdata
+
+import (
+	"fmt"
+
+	//reviser:off
+	"strings"
+	"bytes" // inside
+	//reviser:on
+)
+
+func main() {
+	fmt.Println(strings.ToUpper(bytes.MinRead))
+}
+`,
+			wantHasChange: true,
+		},
+		{
+			name: "success with import inside and outside of region",
+			src: `package testdata
+
+import (
+	//reviser:off
+	"strings"
+	"bytes" // inside
+	//reviser:on
+
+	"bytes" // outside
+	"fmt"
+)
+
+func main() {
+	fmt.Println(strings.ToUpper(bytes.MinRead))
+}
+`,
+			want: `package testdata
+
+import (
+	"fmt"
+
+	//reviser:off
+	"strings"
+	"bytes" // inside
+	//reviser:on
+)
+
+func main() {
+	fmt.Println(strings.ToUpper(bytes.MinRead))
+}
+`,
+			wantHasChange: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(Config{
+				ProjectName: "github.com/psawicki5/goimports-reviser",
+				Options:     tt.options,
+			})
+			require.NoError(t, err)
+
+			got := reviseExample(t, r, tt.src)
+
+			var notes []string
+			for _, note := range got.Notes {
+				notes = append(notes, note.String())
+			}
+
+			assert.Equal(t, tt.want, string(got.Content))
+			assert.Equal(t, tt.wantHasChange, got.HasChange)
+			assert.Equal(t, tt.wantNotes, notes)
+		})
+	}
+}
+
+func TestReviser_ImportGroups(t *testing.T) {
+	const src = `package testdata
+
+import (
+	"fmt"
+
+	"github.com/psawicki5/goimports-reviser/v2/pkg/module"
+
+	"github.com/pkg/errors"
+)
+
+var _, _, _ = fmt.Sprint, module.GoModRootPath, errors.New
+`
+
+	tests := []struct {
+		name   string
+		groups ImportGroups
+		src    string
+		want   string
+	}{
+		{
+			name:   "success with custom groups",
+			groups: ParseImportGroups("std,general+project"),
+			src:    src,
+			want: `package testdata
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/psawicki5/goimports-reviser/v2/pkg/module"
+)
+
+var _, _, _ = fmt.Sprint, module.GoModRootPath, errors.New
+`,
+		},
+		{
+			name:   "success with reordered groups",
+			groups: ParseImportGroups("project,general"),
+			src:    src,
+			want: `package testdata
+
+import (
+	"github.com/psawicki5/goimports-reviser/v2/pkg/module"
+
+	"fmt"
+	"github.com/pkg/errors"
+)
+
+var _, _, _ = fmt.Sprint, module.GoModRootPath, errors.New
+`,
+		},
+		{
+			name:   "success with generated file",
+			groups: ParseImportGroups("std,general+project"),
+			src:    "// Code generated by mockgen. DO NOT EDIT.\n\n" + src,
+			want: `// Code generated by mockgen. DO NOT EDIT.
+
+package testdata
+
+import (
+	"fmt"
+
+	"github.com/psawicki5/goimports-reviser/v2/pkg/module"
+
+	"github.com/pkg/errors"
+)
+
+var _, _, _ = fmt.Sprint, module.GoModRootPath, errors.New
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(Config{
+				ProjectName:           "github.com/psawicki5/goimports-reviser",
+				ImportGroups:          tt.groups,
+				GeneratedImportGroups: ParseImportGroups("std,project,general"),
+			})
+			require.NoError(t, err)
+
+			got := reviseExample(t, r, tt.src)
+
+			assert.Equal(t, tt.want, string(got.Content))
+		})
+	}
+}
+
+func TestNew_InvalidImportGroups(t *testing.T) {
+	_, err := New(Config{ImportGroups: ParseImportGroups("std")})
+
+	var configErr *ConfigError
+	assert.True(t, errors.As(err, &configErr))
+	assert.EqualError(t, err, `invalid configuration: import groups: group matcher "general" is not used`)
+}
+
+func TestReviser_BlankImports(t *testing.T) {
+	const src = `package testdata
+
+import (
+	"strings"
+	_ "net/http/pprof"
+	_ "image/png"
+	"fmt"
+	_ "expvar"
+
+	_ "github.com/pkg/errors"
+	"github.com/go-pg/pg/v9"
+)
+
+var _, _, _ = strings.ToUpper, fmt.Sprint, pg.Connect
+`
+
+	tests := []struct {
+		name    string
+		options Options
+		groups  ImportGroups
+		want    string
+	}{
+		{
+			name:   "success with blank group",
+			groups: ParseImportGroups("std,local,project,general,blank"),
+			want: `package testdata
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-pg/pg/v9"
+
+	_ "net/http/pprof"
+	_ "image/png"
+	_ "expvar"
+	_ "github.com/pkg/errors"
+)
+
+var _, _, _ = strings.ToUpper, fmt.Sprint, pg.Connect
+`,
+		},
+		{
+			name:    "success with blank imports in place",
+			options: Options{OptionKeepBlankImportsInPlace},
+			want: `package testdata
+
+import (
+	"fmt"
+	_ "net/http/pprof"
+	_ "image/png"
+	"strings"
+	_ "expvar"
+
+	_ "github.com/pkg/errors"
+	"github.com/go-pg/pg/v9"
+)
+
+var _, _, _ = strings.ToUpper, fmt.Sprint, pg.Connect
+`,
+		},
+		{
+			name: "success with sorted blank imports",
+			want: `package testdata
+
+import (
+	_ "expvar"
+	"fmt"
+	_ "image/png"
+	_ "net/http/pprof"
+	"strings"
+
+	"github.com/go-pg/pg/v9"
+	_ "github.com/pkg/errors"
+)
+
+var _, _, _ = strings.ToUpper, fmt.Sprint, pg.Connect
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(Config{
+				ProjectName:  "github.com/psawicki5/goimports-reviser",
+				Options:      tt.options,
+				ImportGroups: tt.groups,
+			})
+			require.NoError(t, err)
+
+			got := reviseExample(t, r, src)
+
+			assert.Equal(t, tt.want, string(got.Content))
+		})
+	}
+}
+
+func TestReviser_SortStrategies(t *testing.T) {
+	const src = `package testdata
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	sq "github.com/Masterminds/squirrel"
+	_ "github.com/lib/pq"
+	zz "github.com/aaa/bbb"
+)
+`
+
+	tests := []struct {
+		name   string
+		groups ImportGroups
+		want   string
+	}{
+		{
+			// the default order is the one of gofmt, which sorts imports of the code written by the reviser
+			name:   "success with default sort strategy",
+			groups: ParseImportGroups("std,general"),
+			want: `package testdata
+
+import (
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	zz "github.com/aaa/bbb"
+	_ "github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+`,
+		},
+		{
+			name:   "success with alias sort strategy",
+			groups: ParseImportGroups("std,general:alias"),
+			want: `package testdata
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	_ "github.com/lib/pq"
+	sq "github.com/Masterminds/squirrel"
+	zz "github.com/aaa/bbb"
+)
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(Config{ImportGroups: tt.groups})
+			require.NoError(t, err)
+
+			got := reviseExample(t, r, src)
+
+			assert.Equal(t, tt.want, string(got.Content))
+		})
+	}
+}
+
+func TestReviser_SubGroups(t *testing.T) {
+	const src = `package testdata
+
+import (
+	"fmt"
+
+	"github.com/go-pg/pg/v9/orm"
+	"golang.org/x/tools/go/packages"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/modfile"
+	"github.com/go-pg/pg/v9"
+)
+`
+
+	tests := []struct {
+		name   string
+		groups ImportGroups
+		want   string
+	}{
+		{
+			name:   "success with sub-groups by modules",
+			groups: ParseImportGroups("std,general@module"),
+			want: `package testdata
+
+import (
+	"fmt"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+
+	"github.com/pkg/errors"
+
+	"golang.org/x/mod/modfile"
+
+	"golang.org/x/tools/go/packages"
+)
+`,
+		},
+		{
+			name:   "success with sub-groups by orgs",
+			groups: ParseImportGroups("std,general@org"),
+			want: `package testdata
+
+import (
+	"fmt"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+
+	"github.com/pkg/errors"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/tools/go/packages"
+)
+`,
+		},
+		{
+			name:   "success with sub-groups by hosts",
+			groups: ParseImportGroups("std,general@host"),
+			want: `package testdata
+
+import (
+	"fmt"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/go-pg/pg/v9/orm"
+	"github.com/pkg/errors"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/tools/go/packages"
+)
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(Config{
+				ProjectName:  "github.com/psawicki5/goimports-reviser",
+				ImportGroups: tt.groups,
+			})
+			require.NoError(t, err)
+
+			got := reviseExample(t, r, src)
+
+			assert.Equal(t, tt.want, string(got.Content))
+		})
+	}
+}
+
+func TestReviser_PreserveSubGroups(t *testing.T) {
+	const src = `package testdata
+
+import (
+	"strings"
+	"fmt"
+
+	"os"
+	"github.com/pkg/errors"
+
+	"golang.org/x/mod/modfile"
+	"github.com/go-pg/pg/v9"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/assert"
+)
+`
+
+	tests := []struct {
+		name    string
+		options Options
+		want    string
+	}{
+		{
+			name:    "success with preserved sub-groups",
+			options: Options{OptionPreserveSubGroups},
+			want: `package testdata
+
+import (
+	"fmt"
+	"strings"
+
+	"os"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/modfile"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+`,
+		},
+		{
+			name: "success with flattened sub-groups",
+			want: `package testdata
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-pg/pg/v9"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/modfile"
+)
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(Config{
+				ProjectName: "github.com/psawicki5/goimports-reviser",
+				Options:     tt.options,
+			})
+			require.NoError(t, err)
+
+			got := reviseExample(t, r, src)
+
+			assert.Equal(t, tt.want, string(got.Content))
+		})
+	}
+}
+
+func TestReviser_ReviseAST(t *testing.T) {
+	r, err := New(Config{ProjectName: "github.com/psawicki5/goimports-reviser"})
+	require.NoError(t, err)
+
+	fset := token.NewFileSet()
+	f := &ast.File{
+		Name: ast.NewIdent("testdata"),
+		Decls: []ast.Decl{
+			&ast.GenDecl{
+				Tok:    token.IMPORT,
+				Lparen: 1,
+				Specs: []ast.Spec{
+					&ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: `"github.com/pkg/errors"`}},
+					&ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: `"fmt"`}},
+				},
+			},
+		},
+	}
+	f.Imports = []*ast.ImportSpec{
+		f.Decls[0].(*ast.GenDecl).Specs[0].(*ast.ImportSpec),
+		f.Decls[0].(*ast.GenDecl).Specs[1].(*ast.ImportSpec),
+	}
+
+	got, err := r.ReviseAST(context.Background(), fset, f, "./testdata/generated.go")
+	require.NoError(t, err)
+
+	assert.True(t, got.HasChange)
+	assert.Equal(t, `package testdata
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+`, string(got.Content))
+}
+
+func TestReviser_ReviseAST_Cgo(t *testing.T) {
+	r, err := New(Config{ProjectName: "github.com/psawicki5/goimports-reviser"})
+	require.NoError(t, err)
+
+	fset := token.NewFileSet()
+	f := &ast.File{
+		Name: ast.NewIdent("testdata"),
+		Decls: []ast.Decl{
+			&ast.GenDecl{
+				Tok:    token.IMPORT,
+				Lparen: 1,
+				Specs: []ast.Spec{
+					&ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: `"strings"`}},
+					&ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: `"C"`}},
+					&ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: `"fmt"`}},
+				},
+			},
+		},
+	}
+	for _, spec := range f.Decls[0].(*ast.GenDecl).Specs {
+		f.Imports = append(f.Imports, spec.(*ast.ImportSpec))
+	}
+
+	got, err := r.ReviseAST(context.Background(), fset, f, "./testdata/generated.go")
+	require.NoError(t, err)
+
+	assert.True(t, got.HasChange)
+	assert.Equal(t, `package testdata
+
+import "C"
+
+import (
+	"fmt"
+	"strings"
+)
+`, string(got.Content))
+}
+
+func TestReviser_Concurrent(t *testing.T) {
+	r, err := New(Config{
+		ProjectName: "github.com/psawicki5/goimports-reviser",
+		Options:     Options{OptionRemoveUnusedImports},
+	})
+	require.NoError(t, err)
+
+	const (
+		revisesCount = 16
+		src          = `package testdata
+
+import (
+	"strings"
+	"bytes"
+)
+
+func main() {
+	_ = bytes.NewBufferString("")
+}
+`
+		want = `package testdata
+
+import (
+	"bytes"
+)
+
+func main() {
+	_ = bytes.NewBufferString("")
+}
+`
+	)
+
+	// the package is loaded from the directory, so it needs a file
+	writeExample(t, "package testdata\n")
+
+	var wg sync.WaitGroup
+	results := make([]*Result, revisesCount)
+	errs := make([]error, revisesCount)
+	for i := 0; i < revisesCount; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			results[i], errs[i] = r.ReviseSource(context.Background(), "./testdata/not_existing.go", []byte(src))
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < revisesCount; i++ {
+		require.NoError(t, errs[i])
+		assert.Equal(t, want, string(results[i].Content))
+	}
+}
+
+func TestReviser_FloatingComments(t *testing.T) {
+	const src = `package testdata
+
+import (
+	// std
+
+	"strings"
+	"fmt"
+
+	// third-party
+
+	"github.com/pkg/errors"
+	// "github.com/go-pg/pg/v9"
+
+	// TODO: drop after migration
+	"github.com/psawicki5/goimports-reviser/testdata/innderpkg"
+	"bytes"
+	// the end
+)
+`
+
+	tests := []struct {
+		name   string
+		policy FloatingCommentsPolicy
+		want   string
+	}{
+		{
+			name:   "attach to the following import",
+			policy: FloatingCommentsAttach,
+			want: `package testdata
+
+import (
+	"bytes"
+	// the end
+	"fmt"
+	// std
+	"strings"
+
+	// "github.com/go-pg/pg/v9"
+	// TODO: drop after migration
+	"github.com/psawicki5/goimports-reviser/testdata/innderpkg"
+
+	// third-party
+	"github.com/pkg/errors"
+)
+`,
+		},
+		{
+			name:   "keep at the top of the group",
+			policy: FloatingCommentsGroupTop,
+			want: `package testdata
+
+import (
+	// std
+	// the end
+	"bytes"
+	"fmt"
+	"strings"
+
+	// "github.com/go-pg/pg/v9"
+	// TODO: drop after migration
+	"github.com/psawicki5/goimports-reviser/testdata/innderpkg"
+
+	// third-party
+	"github.com/pkg/errors"
+)
+`,
+		},
+		{
+			name:   "place at the top of the block",
+			policy: FloatingCommentsBlockTop,
+			want: `package testdata
+
+import (
+	// std
+	// third-party
+	// "github.com/go-pg/pg/v9"
+	// the end
+	"bytes"
+	"fmt"
+	"strings"
+
+	// TODO: drop after migration
+	"github.com/psawicki5/goimports-reviser/testdata/innderpkg"
+
+	"github.com/pkg/errors"
+)
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(Config{
+				ProjectName:      "github.com/psawicki5/goimports-reviser",
+				FloatingComments: tt.policy,
+			})
+			require.NoError(t, err)
+
+			got, err := r.ReviseSource(context.Background(), "./testdata/example.go", []byte(src))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, string(got.Content))
+		})
+	}
+}
+
+func TestReviser_FloatingComments_AllImportsRemoved(t *testing.T) {
+	r, err := New(Config{
+		ProjectName: "github.com/psawicki5/goimports-reviser",
+		Options:     Options{OptionRemoveUnusedImports},
+	})
+	require.NoError(t, err)
+
+	got := reviseExample(t, r, `package testdata
+
+import (
+	// "fmt"
+
+	"strings"
+)
+`)
+
+	assert.Equal(t, `package testdata
+
+// "fmt"
+`, string(got.Content))
+}
+
+const exampleFilePath = "testdata/example.go"
+
+// reviseExample writes the code to testdata/example.go, so that the package of the file can be loaded, and revises it.
+// The file is removed once the test finishes.
+func reviseExample(t *testing.T, r *Reviser, src string) *Result {
+	t.Helper()
+
+	writeExample(t, src)
+
+	got, err := r.ReviseSource(context.Background(), exampleFilePath, []byte(src))
+	require.NoError(t, err)
+
+	return got
+}
+
+// writeExample writes the code to testdata/example.go, the file is removed once the test finishes
+func writeExample(t *testing.T, src string) {
+	t.Helper()
+
+	require.NoError(t, ioutil.WriteFile(exampleFilePath, []byte(src), 0644))
+	t.Cleanup(func() {
+		_ = os.Remove(exampleFilePath)
+	})
+}
@@ -0,0 +1,149 @@
+package reviser
+
+import (
+	"sort"
+	"strings"
+)
+
+// SortStrategy defines the order of imports within a group
+type SortStrategy string
+
+const (
+	// SortByPath sorts imports by paths and then by aliases, like goimports and gofmt do
+	SortByPath SortStrategy = "path"
+
+	// SortByAlias sorts imports by aliases(imports without aliases go first) and then by paths
+	SortByAlias SortStrategy = "alias"
+
+	// SortNatural sorts imports by paths, but numbers in paths are compared by their values(ex.: `pg/v9` goes before
+	// `pg/v10`)
+	SortNatural SortStrategy = "natural"
+
+	// SortCaseInsensitive sorts imports by paths ignoring case
+	SortCaseInsensitive SortStrategy = "case-insensitive"
+
+	// SortBySource keeps the order of the original code, added imports go first
+	SortBySource SortStrategy = "source"
+)
+
+// importComparators are comparators of imports by sort strategies, which don't depend on the original code
+var importComparators = map[SortStrategy]func(a, b string) bool{
+	SortByPath: lessByPath,
+	SortByAlias: func(a, b string) bool {
+		if aliasA, aliasB := importAlias(a), importAlias(b); aliasA != aliasB {
+			return aliasA < aliasB
+		}
+
+		return lessByPath(a, b)
+	},
+	SortNatural: func(a, b string) bool {
+		if cmp := compareNatural(skipPackageAlias(a), skipPackageAlias(b)); cmp != 0 {
+			return cmp < 0
+		}
+
+		return lessByPath(a, b)
+	},
+	SortCaseInsensitive: func(a, b string) bool {
+		pathA, pathB := strings.ToLower(skipPackageAlias(a)), strings.ToLower(skipPackageAlias(b))
+		if pathA != pathB {
+			return pathA < pathB
+		}
+
+		return lessByPath(a, b)
+	},
+}
+
+// sortImports sorts imports with the strategy
+func sortImports(imports []string, strategy SortStrategy, importsWithMetadata map[string]*commentsMetadata) {
+	if strategy == SortBySource {
+		sortImportsBySource(imports, importsWithMetadata)
+		return
+	}
+
+	less := importComparators[strategy]
+	sort.Slice(imports, func(i, j int) bool {
+		return less(imports[i], imports[j])
+	})
+}
+
+// lessByPath compares imports by paths and then by names
+func lessByPath(a, b string) bool {
+	if pathA, pathB := skipPackageAlias(a), skipPackageAlias(b); pathA != pathB {
+		return pathA < pathB
+	}
+
+	return importAlias(a) < importAlias(b)
+}
+
+// compareNatural compares strings by chunks of digits and non-digits, chunks of digits are compared by their values
+func compareNatural(a, b string) int {
+	for a != "" && b != "" {
+		var chunkA, chunkB string
+		chunkA, a = leadingChunk(a)
+		chunkB, b = leadingChunk(b)
+
+		if chunkA == chunkB {
+			continue
+		}
+
+		if isDigit(chunkA[0]) && isDigit(chunkB[0]) {
+			numberA, numberB := strings.TrimLeft(chunkA, "0"), strings.TrimLeft(chunkB, "0")
+			if len(numberA) != len(numberB) {
+				return len(numberA) - len(numberB)
+			}
+
+			if numberA != numberB {
+				return strings.Compare(numberA, numberB)
+			}
+		}
+
+		return strings.Compare(chunkA, chunkB)
+	}
+
+	return len(a) - len(b)
+}
+
+// leadingChunk splits the string into the leading chunk of digits or non-digits and the rest of the string
+func leadingChunk(s string) (string, string) {
+	i := 1
+	for i < len(s) && isDigit(s[i]) == isDigit(s[0]) {
+		i++
+	}
+
+	return s[:i], s[i:]
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// sortImportsBySource sorts imports in the order of the original code, added imports precede others in the order of
+// strings
+func sortImportsBySource(imports []string, importsWithMetadata map[string]*commentsMetadata) {
+	sort.Strings(imports)
+	sort.SliceStable(imports, func(i, j int) bool {
+		return importsWithMetadata[imports[i]].Pos < importsWithMetadata[imports[j]].Pos
+	})
+}
+
+// placeBlankImports moves blank imports of the sorted group to their places in the original code: positions of the
+// group, which are taken by blank imports in the original code, are kept by blank imports in their original order
+func placeBlankImports(group []string, importsWithMetadata map[string]*commentsMetadata) {
+	bySource := append([]string(nil), group...)
+	sortImportsBySource(bySource, importsWithMetadata)
+
+	others := make([]string, 0, len(group))
+	for _, imprt := range group {
+		if importAlias(imprt) != "_" {
+			others = append(others, imprt)
+		}
+	}
+
+	for i, imprt := range bySource {
+		if importAlias(imprt) != "_" {
+			imprt, others = others[0], others[1:]
+		}
+
+		group[i] = imprt
+	}
+}
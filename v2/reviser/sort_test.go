@@ -0,0 +1,115 @@
+package reviser
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortImports(t *testing.T) {
+	imports := []string{
+		`"github.com/go-pg/pg/v10"`,
+		`pgv9 "github.com/go-pg/pg/v9"`,
+		`"github.com/Masterminds/semver"`,
+		`"github.com/go-pg/pg/v9/orm"`,
+		`errors "github.com/pkg/errors"`,
+		`"github.com/golang/protobuf/proto"`,
+	}
+
+	importsWithMetadata := make(map[string]*commentsMetadata, len(imports))
+	for i, imprt := range imports {
+		importsWithMetadata[imprt] = &commentsMetadata{Pos: token.Pos(i + 1)}
+	}
+
+	tests := []struct {
+		strategy SortStrategy
+		want     []string
+	}{
+		{
+			strategy: SortByPath,
+			want: []string{
+				`"github.com/Masterminds/semver"`,
+				`"github.com/go-pg/pg/v10"`,
+				`pgv9 "github.com/go-pg/pg/v9"`,
+				`"github.com/go-pg/pg/v9/orm"`,
+				`"github.com/golang/protobuf/proto"`,
+				`errors "github.com/pkg/errors"`,
+			},
+		},
+		{
+			strategy: SortByAlias,
+			want: []string{
+				`"github.com/Masterminds/semver"`,
+				`"github.com/go-pg/pg/v10"`,
+				`"github.com/go-pg/pg/v9/orm"`,
+				`"github.com/golang/protobuf/proto"`,
+				`errors "github.com/pkg/errors"`,
+				`pgv9 "github.com/go-pg/pg/v9"`,
+			},
+		},
+		{
+			strategy: SortNatural,
+			want: []string{
+				`"github.com/Masterminds/semver"`,
+				`pgv9 "github.com/go-pg/pg/v9"`,
+				`"github.com/go-pg/pg/v9/orm"`,
+				`"github.com/go-pg/pg/v10"`,
+				`"github.com/golang/protobuf/proto"`,
+				`errors "github.com/pkg/errors"`,
+			},
+		},
+		{
+			strategy: SortCaseInsensitive,
+			want: []string{
+				`"github.com/go-pg/pg/v10"`,
+				`pgv9 "github.com/go-pg/pg/v9"`,
+				`"github.com/go-pg/pg/v9/orm"`,
+				`"github.com/golang/protobuf/proto"`,
+				`"github.com/Masterminds/semver"`,
+				`errors "github.com/pkg/errors"`,
+			},
+		},
+		{
+			strategy: SortBySource,
+			want:     imports,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			got := append([]string(nil), imports...)
+			sortImports(got, tt.strategy, importsWithMetadata)
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompareNatural(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "pg/v9", b: "pg/v10", want: -1},
+		{a: "pg/v10", b: "pg/v9", want: 1},
+		{a: "pg/v9", b: "pg/v9", want: 0},
+		{a: "pg/v9", b: "pg/v9/orm", want: -1},
+		{a: "pg/v09", b: "pg/v9", want: -1},
+		{a: "a1", b: "ab", want: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			got := compareNatural(tt.a, tt.b)
+			switch {
+			case tt.want < 0:
+				assert.True(t, got < 0)
+			case tt.want > 0:
+				assert.True(t, got > 0)
+			default:
+				assert.Zero(t, got)
+			}
+		})
+	}
+}